@@ -18,9 +18,12 @@ if %errorlevel% neq 0 (
     exit /b 1
 )
 
+for /f %%i in ('git rev-parse --short HEAD 2^>nul') do set COMMIT=%%i
+if "%VERSION%"=="" set VERSION=dev
+
 echo.
 echo 🏗️ Building LAMZU Automator (Console mode - with console window)...
-"C:\Program Files\Go\bin\go.exe" build -ldflags="-s -w" -o lamzu-automator-console.exe .
+"C:\Program Files\Go\bin\go.exe" build -ldflags="-s -w -X main.version=%VERSION% -X main.commit=%COMMIT%" -o lamzu-automator-console.exe .
 if %errorlevel% neq 0 (
     echo ❌ Build failed
     pause
@@ -18,9 +18,12 @@ if %errorlevel% neq 0 (
     exit /b 1
 )
 
+for /f %%i in ('git rev-parse --short HEAD 2^>nul') do set COMMIT=%%i
+if "%VERSION%"=="" set VERSION=dev
+
 echo.
 echo 🏗️ Building LAMZU Automator (GUI mode - no console window)...
-go build -ldflags="-s -w -H=windowsgui" -o lamzu-automator.exe .
+go build -ldflags="-s -w -X main.version=%VERSION% -X main.commit=%COMMIT% -H=windowsgui" -o lamzu-automator.exe .
 if %errorlevel% neq 0 (
     echo ❌ Build failed
     pause
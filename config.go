@@ -3,25 +3,113 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	DefaultPollingRate int           `yaml:"default_polling_rate"`
-	GamePollingRate    int           `yaml:"game_polling_rate"`
-	CheckInterval      time.Duration `yaml:"check_interval"`
-	Games              []string      `yaml:"games"` // Legacy support
-	Steam              *SteamConfig  `yaml:"steam,omitempty"`
-	DetectedGames      []Game        `yaml:"detected_games,omitempty"`
-	CustomGames        []CustomGame  `yaml:"custom_games,omitempty"`
+	DefaultPollingRate     int                    `yaml:"default_polling_rate"`
+	GamePollingRate        int                    `yaml:"game_polling_rate"`
+	CheckInterval          time.Duration          `yaml:"check_interval"`
+	RestoreOnExit          bool                   `yaml:"restore_on_exit,omitempty"`
+	ReconcileInterval      time.Duration          `yaml:"reconcile_interval,omitempty"` // 0 disables read-back checks
+	OnSwitchCommand        string                 `yaml:"on_switch_command,omitempty"`  // Supports {rate} and {game} placeholders
+	OnSwitchWebhook        string                 `yaml:"on_switch_webhook,omitempty"`
+	Schedule               []ScheduleEntry        `yaml:"schedule,omitempty"`                 // Time-of-day base rates, first match wins
+	ReassertTicks          int                    `yaml:"reassert_ticks,omitempty"`           // Checks between re-applies for reassert games (default 5)
+	ProcessAliases         map[string]string      `yaml:"process_aliases,omitempty"`          // Actual process name -> friendly game name
+	NotifyCooldown         time.Duration          `yaml:"notification_cooldown"`              // Minimum gap between notifications of the same kind
+	Modifiers              []ModifierRule         `yaml:"modifiers,omitempty"`                // Rate caps while certain apps (e.g. OBS) run
+	PauseDuring            []string               `yaml:"pause_during,omitempty"`             // While any of these run, no rate changes are made at all
+	LoadTrigger            *LoadTrigger           `yaml:"load_trigger,omitempty"`             // Also use the game rate while CPU/GPU load stays high
+	Device                 *DeviceProfile         `yaml:"device,omitempty"`                   // Protocol overrides for other models/firmwares
+	MatchCaseSensitive     bool                   `yaml:"match_case_sensitive,omitempty"`     // Matching ignores case unless set
+	MatchIgnoreExtension   bool                   `yaml:"match_ignore_extension,omitempty"`   // Compare names without .exe/.bat/etc.
+	PersistPaths           bool                   `yaml:"persist_discovered_paths,omitempty"` // Save game paths found at runtime back to the config
+	PauseHotkey            string                 `yaml:"pause_hotkey,omitempty"`             // e.g. "Ctrl+Alt+P"; empty disables the hotkey
+	DisableLegacyGames     bool                   `yaml:"disable_legacy_games,omitempty"`     // Migrate games into custom_games and stop using it
+	Profiles               map[string]RateProfile `yaml:"profiles,omitempty"`                 // Named rate sets
+	ShutdownProfile        string                 `yaml:"shutdown_profile,omitempty"`         // Profile whose default rate is applied on exit
+	ActiveProfile          string                 `yaml:"active_profile,omitempty"`           // Profile whose rates replace the top-level ones at startup
+	PreferForegroundGame   bool                   `yaml:"prefer_foreground_game,omitempty"`   // With several games running, the focused one's rate wins instead of the highest
+	MaxPollingRate         int                    `yaml:"max_polling_rate,omitempty"`         // Ceiling for every applied rate (manual, game, profile)
+	StartupDelay           time.Duration          `yaml:"startup_delay,omitempty"`            // Wait before the first rate change (default 1s)
+	StartupTimeout         time.Duration          `yaml:"startup_timeout,omitempty"`          // How long to keep retrying a device that isn't ready (default 30s)
+	IncludeSystemProcesses bool                   `yaml:"include_system_processes,omitempty"` // Match against services and session 0 processes too
+	IgnoreMinimized        bool                   `yaml:"ignore_minimized,omitempty"`         // Minimized games don't count as running
+	ForegroundIgnore       []string               `yaml:"foreground_ignore,omitempty"`        // Extra windows that do not count as leaving the game
+	DetectionMode          string                 `yaml:"detection_mode,omitempty"`           // running (default) or foreground: only the focused game counts
+	FocusGrace             time.Duration          `yaml:"focus_grace,omitempty"`              // foreground mode: how long focus may leave the game before it stops counting (default 3s)
+	ExeSearchSubdirs       []string               `yaml:"exe_search_subdirs,omitempty"`       // Extra folders under each install dir checked for the game executable
+	Games                  []string               `yaml:"games,omitempty"`                    // Legacy support
+	Steam                  *SteamConfig           `yaml:"steam,omitempty"`
+	DetectedGames          []Game                 `yaml:"detected_games,omitempty"`
+	DetectedEpicGames      []Game                 `yaml:"detected_epic_games,omitempty"`    // Found by scan-epic
+	DetectedUbisoftGames   []Game                 `yaml:"detected_ubisoft_games,omitempty"` // Found by scan-steam --ubisoft
+	CustomGames            []CustomGame           `yaml:"custom_games,omitempty"`
+}
+
+// detectedGameLists returns the scanned game sections, Steam first, for code
+// that treats every launcher's games alike
+func (c *Config) detectedGameLists() []*[]Game {
+	return []*[]Game{&c.DetectedGames, &c.DetectedEpicGames, &c.DetectedUbisoftGames}
+}
+
+// gameCount is the number of games the config lists across every section
+func (c *Config) gameCount() int {
+	count := len(c.Games) + len(c.CustomGames)
+	for _, games := range c.detectedGameLists() {
+		count += len(*games)
+	}
+	return count
+}
+
+// detection_mode values
+const (
+	detectionRunning    = "running"    // Any running game process counts
+	detectionForeground = "foreground" // Only a game owning the foreground window counts
+)
+
+// defaultFocusGrace is used when focus_grace isn't configured
+const defaultFocusGrace = 3 * time.Second
+
+// RateProfile is a named pair of rates, e.g. a battery-friendly "saver" profile
+type RateProfile struct {
+	DefaultPollingRate int `yaml:"default_polling_rate"`
+	GamePollingRate    int `yaml:"game_polling_rate,omitempty"`
+}
+
+// applyActiveProfile swaps in the active profile's rates for this run. A profile
+// that no longer exists falls back to the top-level rates with a warning.
+func applyActiveProfile(config *Config) {
+	if config.ActiveProfile == "" {
+		return
+	}
+
+	profile, ok := config.Profiles[config.ActiveProfile]
+	if !ok {
+		logWarnf("⚠️ Active profile %q not found, using default rates\n", config.ActiveProfile)
+		return
+	}
+
+	config.DefaultPollingRate = profile.DefaultPollingRate
+	if profile.GamePollingRate != 0 {
+		config.GamePollingRate = profile.GamePollingRate
+	}
+	logInfof("🗂️ Using profile %s\n", config.ActiveProfile)
 }
 
 type SteamConfig struct {
-	InstallPath string    `yaml:"install_path"`
-	Libraries   []Library `yaml:"libraries"`
-	LastScan    time.Time `yaml:"last_scan"`
+	InstallPath   string            `yaml:"install_path"`
+	Libraries     []Library         `yaml:"libraries"`
+	LastScan      time.Time         `yaml:"last_scan"`
+	SearchDrives  []string          `yaml:"search_drives,omitempty"`     // Drive letters swept when Steam isn't found elsewhere
+	MergeScans    bool              `yaml:"merge_scans,omitempty"`       // Keep games from libraries missing in later scans
+	Unmounted     bool              `yaml:"include_unmounted,omitempty"` // Scan libraries Steam marks as not mounted
+	NameOverrides map[string]string `yaml:"name_overrides,omitempty"`    // AppID -> name used instead of the manifest name for display and executable matching
 }
 
 type Library struct {
@@ -30,26 +118,53 @@ type Library struct {
 }
 
 type Game struct {
+	Name        string    `yaml:"name"`
+	AppID       string    `yaml:"app_id"`
+	Executable  string    `yaml:"executable"`
+	InstallPath string    `yaml:"install_path"`
+	Library     string    `yaml:"library"`
+	SizeMB      int64     `yaml:"size_mb"`
+	LastUpdated time.Time `yaml:"last_updated,omitempty"`
+	PollingRate int       `yaml:"polling_rate,omitempty"` // Rate while this game runs; 0 uses game_polling_rate
+	CloseRate   int       `yaml:"close_rate,omitempty"`
+	Reassert    bool      `yaml:"reassert,omitempty"` // Re-apply the game rate periodically while running
+	// Only match when the process was started (directly or not) by this executable
+	ParentExecutable string `yaml:"parent_executable,omitempty"`
+	// Rate tiers chosen by the game window's height and its monitor's refresh rate
+	ResolutionRates []ResolutionRule `yaml:"resolution_rates,omitempty"`
+	RefreshRates    []RefreshRule    `yaml:"refresh_rates,omitempty"`
+	LastSeen        time.Time        `yaml:"last_seen,omitempty"` // Last time the watcher saw it running
+	Enabled         *bool            `yaml:"enabled,omitempty"`   // nil means enabled; false stops the game triggering switches
+}
+
+type CustomGame struct {
 	Name        string `yaml:"name"`
-	AppID       string `yaml:"app_id"`
 	Executable  string `yaml:"executable"`
-	InstallPath string `yaml:"install_path"`
-	Library     string `yaml:"library"`
-	SizeMB      int64  `yaml:"size_mb"`
+	Path        string `yaml:"path"`
+	PollingRate int    `yaml:"polling_rate,omitempty"` // Rate while this game runs; 0 uses game_polling_rate
+	CloseRate   int    `yaml:"close_rate,omitempty"`
+	Reassert    bool   `yaml:"reassert,omitempty"`
+	// Only match when the process was started (directly or not) by this executable
+	ParentExecutable string `yaml:"parent_executable,omitempty"`
+	// Rate tiers chosen by the game window's height and its monitor's refresh rate
+	ResolutionRates []ResolutionRule `yaml:"resolution_rates,omitempty"`
+	RefreshRates    []RefreshRule    `yaml:"refresh_rates,omitempty"`
+	LastSeen        time.Time        `yaml:"last_seen,omitempty"` // Last time the watcher saw it running
+	Enabled         *bool            `yaml:"enabled,omitempty"`   // nil means enabled; false stops the game triggering switches
 }
 
-type CustomGame struct {
-	Name       string `yaml:"name"`
-	Executable string `yaml:"executable"`
-	Path       string `yaml:"path"`
+// gameEnabled reports whether a game entry's enabled flag leaves it active
+func gameEnabled(enabled *bool) bool {
+	return enabled == nil || *enabled
 }
 
-func LoadConfig(filename string) (*Config, error) {
-	// Default configuration
-	config := &Config{
+// DefaultConfig returns the built-in configuration used when no file exists yet
+func DefaultConfig() *Config {
+	return &Config{
 		DefaultPollingRate: 1000,
 		GamePollingRate:    2000,
 		CheckInterval:      5 * time.Second,
+		NotifyCooldown:     15 * time.Second,
 		Steam: &SteamConfig{
 			InstallPath: "",
 			Libraries:   []Library{},
@@ -65,6 +180,11 @@ func LoadConfig(filename string) (*Config, error) {
 			{Name: "Apex Legends", Executable: "ApexLegends.exe", Path: ""},
 		},
 	}
+}
+
+func LoadConfig(filename string) (*Config, error) {
+	// Default configuration
+	config := DefaultConfig()
 
 	// Try to load from file
 	data, err := os.ReadFile(filename)
@@ -74,7 +194,7 @@ func LoadConfig(filename string) (*Config, error) {
 			if err := SaveConfig(config, filename); err != nil {
 				return nil, fmt.Errorf("failed to create default config: %w", err)
 			}
-			fmt.Printf("📄 Created default config file: %s\n", filename)
+			noticef("📄 Created default config file: %s\n", filename)
 			return config, nil
 		}
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -84,9 +204,180 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	if config.DisableLegacyGames && len(config.Games) > 0 {
+		migrated := migrateLegacyGames(config)
+		if err := newBackgroundConfigUpdater(filename).saveConfigAtomic(config); err != nil {
+			return nil, fmt.Errorf("failed to save migrated legacy games: %w", err)
+		}
+		logInfof("🔄 Moved %d legacy games to custom_games\n", migrated)
+	}
+
+	// Ubisoft games used to be saved among the Steam games
+	if migrated := migrateUbisoftGames(config); migrated > 0 {
+		if err := newBackgroundConfigUpdater(filename).saveConfigAtomic(config); err != nil {
+			return nil, fmt.Errorf("failed to save migrated Ubisoft games: %w", err)
+		}
+		logInfof("🔄 Moved %d Ubisoft games to detected_ubisoft_games\n", migrated)
+	}
+
+	// Rates added by rate_bytes must be known before the rest of the config is validated
+	if config.Device != nil && len(validateRateBytes(config.Device.RateBytes)) == 0 {
+		applyRateBytes(config.Device.RateBytes)
+	}
+
 	return config, nil
 }
 
+// migrateLegacyGames moves the legacy games list into custom games, skipping
+// executables already listed there, and returns how many were added
+func migrateLegacyGames(config *Config) int {
+	existing := make(map[string]bool, len(config.CustomGames))
+	for _, game := range config.CustomGames {
+		existing[strings.ToLower(game.Executable)] = true
+	}
+
+	migrated := 0
+	for _, game := range convertLegacyGames(config.Games) {
+		if existing[strings.ToLower(game.Executable)] {
+			continue
+		}
+		existing[strings.ToLower(game.Executable)] = true
+		config.CustomGames = append(config.CustomGames, game)
+		migrated++
+	}
+
+	config.Games = nil
+	return migrated
+}
+
+// migrateUbisoftGames moves Ubisoft games out of detected_games into their
+// own section and returns how many were moved
+func migrateUbisoftGames(config *Config) int {
+	var ubisoft []Game
+	config.DetectedGames, ubisoft = splitUbisoftGames(config.DetectedGames)
+	config.DetectedUbisoftGames = append(config.DetectedUbisoftGames, ubisoft...)
+	return len(ubisoft)
+}
+
+// ValidateConfig checks the loaded configuration for values the watcher can't use
+func ValidateConfig(config *Config) []error {
+	var problems []error
+
+	if _, ok := pollingRateMap[config.DefaultPollingRate]; !ok {
+		problems = append(problems, fmt.Errorf("default_polling_rate: unsupported rate %d", config.DefaultPollingRate))
+	}
+	if _, ok := pollingRateMap[config.GamePollingRate]; !ok {
+		problems = append(problems, fmt.Errorf("game_polling_rate: unsupported rate %d", config.GamePollingRate))
+	}
+	if config.CheckInterval <= 0 {
+		problems = append(problems, fmt.Errorf("check_interval: must be greater than zero"))
+	}
+	if config.NotifyCooldown < 0 {
+		problems = append(problems, fmt.Errorf("notification_cooldown: must not be negative"))
+	}
+	if config.ReassertTicks < 0 {
+		problems = append(problems, fmt.Errorf("reassert_ticks: must not be negative"))
+	}
+	if config.ReconcileInterval < 0 {
+		problems = append(problems, fmt.Errorf("reconcile_interval: must not be negative"))
+	}
+
+	switch config.DetectionMode {
+	case "", detectionRunning, detectionForeground:
+	default:
+		problems = append(problems, fmt.Errorf("detection_mode: must be %s or %s, got %q", detectionRunning, detectionForeground, config.DetectionMode))
+	}
+	if config.FocusGrace < 0 {
+		problems = append(problems, fmt.Errorf("focus_grace: must not be negative"))
+	}
+
+	for _, subdir := range config.ExeSearchSubdirs {
+		if filepath.IsAbs(subdir) || filepath.VolumeName(subdir) != "" || strings.HasPrefix(filepath.Clean(subdir), "..") {
+			problems = append(problems, fmt.Errorf("exe_search_subdirs: %q must be relative to the game's install folder", subdir))
+		}
+	}
+
+	problems = append(problems, validateSchedule(config.Schedule)...)
+	problems = append(problems, validateModifiers(config.Modifiers)...)
+	problems = append(problems, validateLoadTrigger(config.LoadTrigger)...)
+
+	for name, profile := range config.Profiles {
+		if _, ok := pollingRateMap[profile.DefaultPollingRate]; !ok {
+			problems = append(problems, fmt.Errorf("profiles.%s.default_polling_rate: unsupported rate %d", name, profile.DefaultPollingRate))
+		}
+		if _, ok := pollingRateMap[profile.GamePollingRate]; profile.GamePollingRate != 0 && !ok {
+			problems = append(problems, fmt.Errorf("profiles.%s.game_polling_rate: unsupported rate %d", name, profile.GamePollingRate))
+		}
+	}
+	if _, ok := config.Profiles[config.ShutdownProfile]; config.ShutdownProfile != "" && !ok {
+		problems = append(problems, fmt.Errorf("shutdown_profile: unknown profile %q", config.ShutdownProfile))
+	}
+
+	if _, ok := pollingRateMap[config.MaxPollingRate]; config.MaxPollingRate != 0 && !ok {
+		problems = append(problems, fmt.Errorf("max_polling_rate: unsupported rate %d", config.MaxPollingRate))
+	}
+
+	if config.Device != nil {
+		problems = append(problems, validateDeviceProfile(config.Device)...)
+	}
+
+	if config.PauseHotkey != "" {
+		if _, _, err := parseHotkey(config.PauseHotkey); err != nil {
+			problems = append(problems, fmt.Errorf("pause_hotkey: %w", err))
+		}
+	}
+
+	for process, name := range config.ProcessAliases {
+		if strings.TrimSpace(process) == "" || strings.TrimSpace(name) == "" {
+			problems = append(problems, fmt.Errorf("process_aliases: empty process or name in %q: %q", process, name))
+		}
+	}
+
+	for i, game := range config.Games {
+		if strings.TrimSpace(game) == "" {
+			problems = append(problems, fmt.Errorf("games[%d]: empty executable", i))
+		}
+	}
+	problems = append(problems, validateDetectedGames("detected_games", config.DetectedGames)...)
+	problems = append(problems, validateDetectedGames("detected_epic_games", config.DetectedEpicGames)...)
+	problems = append(problems, validateDetectedGames("detected_ubisoft_games", config.DetectedUbisoftGames)...)
+	for i, game := range config.CustomGames {
+		if strings.TrimSpace(game.Executable) == "" {
+			problems = append(problems, fmt.Errorf("custom_games[%d] (%s): missing executable", i, game.Name))
+		}
+		if game.CloseRate != 0 {
+			if _, ok := pollingRateMap[game.CloseRate]; !ok {
+				problems = append(problems, fmt.Errorf("custom_games[%d] (%s): unsupported close_rate %d", i, game.Name, game.CloseRate))
+			}
+		}
+		if _, ok := pollingRateMap[game.PollingRate]; game.PollingRate != 0 && !ok {
+			problems = append(problems, fmt.Errorf("custom_games[%d] (%s): unsupported polling_rate %d", i, game.Name, game.PollingRate))
+		}
+		problems = append(problems, validateResolutionRules(fmt.Sprintf("custom_games[%d]", i), game.ResolutionRates)...)
+		problems = append(problems, validateRefreshRules(fmt.Sprintf("custom_games[%d]", i), game.RefreshRates)...)
+	}
+
+	return problems
+}
+
+// validateDetectedGames checks the per-game rates of a scanned game section
+func validateDetectedGames(field string, games []Game) []error {
+	var problems []error
+	for i, game := range games {
+		if game.CloseRate != 0 {
+			if _, ok := pollingRateMap[game.CloseRate]; !ok {
+				problems = append(problems, fmt.Errorf("%s[%d] (%s): unsupported close_rate %d", field, i, game.Name, game.CloseRate))
+			}
+		}
+		if _, ok := pollingRateMap[game.PollingRate]; game.PollingRate != 0 && !ok {
+			problems = append(problems, fmt.Errorf("%s[%d] (%s): unsupported polling_rate %d", field, i, game.Name, game.PollingRate))
+		}
+		problems = append(problems, validateResolutionRules(fmt.Sprintf("%s[%d]", field, i), game.ResolutionRates)...)
+		problems = append(problems, validateRefreshRules(fmt.Sprintf("%s[%d]", field, i), game.RefreshRates)...)
+	}
+	return problems
+}
+
 func SaveConfig(config *Config, filename string) error {
 	data, err := yaml.Marshal(config)
 	if err != nil {
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,8 +15,15 @@ import (
 // ConfigUpdater handles updating the config.yaml file with Steam games
 type ConfigUpdater struct {
 	configPath string
+
+	// skipUndo leaves the undo snapshot alone, for writes the user didn't ask
+	// for (sightings, migrations) that would otherwise replace it
+	skipUndo bool
 }
 
+// undoSuffix is appended to the config path for the one-deep undo snapshot
+const undoSuffix = ".undo"
+
 // NewConfigUpdater creates a new config updater instance
 func NewConfigUpdater(configPath string) *ConfigUpdater {
 	return &ConfigUpdater{
@@ -22,42 +31,211 @@ func NewConfigUpdater(configPath string) *ConfigUpdater {
 	}
 }
 
-// UpdateWithSteamData updates the config with Steam installation and game data
-func (cu *ConfigUpdater) UpdateWithSteamData(steamPath string, libraries []Library, games []Game) error {
+// newBackgroundConfigUpdater creates an updater for automatic writes, which
+// keep the undo snapshot of the user's last change
+func newBackgroundConfigUpdater(configPath string) *ConfigUpdater {
+	return &ConfigUpdater{
+		configPath: configPath,
+		skipUndo:   true,
+	}
+}
+
+// ScanChanges summarizes what a scan did to the detected game list
+type ScanChanges struct {
+	Added  int    // Games not in the config before
+	Pruned []Game // Games dropped by --prune because their install path is gone
+}
+
+// UpdateWithSteamData updates the config with Steam installation and game data.
+// When additive is set, games and libraries missing from this scan are kept as long
+// as they still exist on disk, so intermittently connected drives don't lose games.
+// With prune, detected games whose install path no longer exists are dropped in
+// the same write.
+func (cu *ConfigUpdater) UpdateWithSteamData(steamPath string, libraries []Library, games []Game, additive, prune bool) (ScanChanges, error) {
+	var changes ScanChanges
+
 	// Load existing config
 	config, err := cu.loadExistingConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load existing config: %w", err)
+		return changes, fmt.Errorf("failed to load existing config: %w", err)
 	}
 
-	// Update Steam configuration
-	config.Steam = &SteamConfig{
-		InstallPath: steamPath,
-		Libraries:   libraries,
-		LastScan:    time.Now(),
+	// Update Steam configuration, keeping user settings in the section
+	if config.Steam == nil {
+		config.Steam = &SteamConfig{}
 	}
+	if additive {
+		libraries = cu.mergeLibraries(config.Steam.Libraries, libraries)
+	}
+	config.Steam.InstallPath = steamPath
+	config.Steam.Libraries = libraries
+	config.Steam.LastScan = time.Now()
 
 	// Update detected games (preserve custom games)
 	oldCustomGames := config.CustomGames
-	config.DetectedGames = games
+	known := make(map[string]bool, len(config.DetectedGames))
+	for _, game := range config.DetectedGames {
+		known[game.AppID] = true
+	}
+	for _, game := range games {
+		if !known[game.AppID] {
+			changes.Added++
+		}
+	}
+	keepUserState(config.DetectedGames, games)
+	if additive {
+		config.DetectedGames = cu.MergeGameLists(config.DetectedGames, games)
+	} else {
+		config.DetectedGames = games
+	}
+	if prune {
+		config.DetectedGames, changes.Pruned = cu.pruneMissingGames(config.DetectedGames)
+	}
 
 	// Merge with existing custom games or convert legacy games
 	if config.CustomGames == nil && len(config.Games) > 0 {
 		// Convert legacy games to custom games
-		config.CustomGames = cu.convertLegacyGames(config.Games)
-		if verbose {
-			fmt.Printf("🔄 Converted %d legacy games to custom games\n", len(config.CustomGames))
-		}
+		config.CustomGames = convertLegacyGames(config.Games)
+		logDebugf("🔄 Converted %d legacy games to custom games\n", len(config.CustomGames))
 	} else {
 		config.CustomGames = oldCustomGames
 	}
 
 	// Save updated config atomically
 	if err := cu.saveConfigAtomic(config); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+		return changes, fmt.Errorf("failed to save config: %w", err)
 	}
 
-	return nil
+	return changes, nil
+}
+
+// UpdateWithEpicGames replaces the detected_epic_games section with a fresh
+// scan, keeping per-game user state, and reports how many games are new
+func (cu *ConfigUpdater) UpdateWithEpicGames(games []Game) (int, error) {
+	return cu.replaceDetectedSection(func(c *Config) *[]Game { return &c.DetectedEpicGames }, games)
+}
+
+// UpdateWithUbisoftGames replaces the detected_ubisoft_games section the same
+// way UpdateWithEpicGames does for Epic
+func (cu *ConfigUpdater) UpdateWithUbisoftGames(games []Game) (int, error) {
+	return cu.replaceDetectedSection(func(c *Config) *[]Game { return &c.DetectedUbisoftGames }, games)
+}
+
+// replaceDetectedSection swaps the games of one launcher's section for a fresh
+// scan and reports how many are new
+func (cu *ConfigUpdater) replaceDetectedSection(section func(*Config) *[]Game, games []Game) (int, error) {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load existing config: %w", err)
+	}
+	detected := section(config)
+
+	known := make(map[string]bool, len(*detected))
+	for _, game := range *detected {
+		known[game.AppID] = true
+	}
+	added := 0
+	for _, game := range games {
+		if !known[game.AppID] {
+			added++
+		}
+	}
+
+	keepUserState(*detected, games)
+	*detected = games
+
+	if err := cu.saveConfigAtomic(config); err != nil {
+		return 0, fmt.Errorf("failed to save config: %w", err)
+	}
+	return added, nil
+}
+
+// splitUbisoftGames separates Ubisoft Connect games, which have their own
+// config section, from the Steam games of a scan
+func splitUbisoftGames(games []Game) (steam, ubisoft []Game) {
+	for _, game := range games {
+		if game.Library == ubisoftLibraryLabel {
+			ubisoft = append(ubisoft, game)
+		} else {
+			steam = append(steam, game)
+		}
+	}
+	return steam, ubisoft
+}
+
+// pruneMissingGames splits games into those still installed and those whose
+// install path is gone
+func (cu *ConfigUpdater) pruneMissingGames(games []Game) (kept, pruned []Game) {
+	kept = make([]Game, 0, len(games))
+	for _, game := range games {
+		if cu.verifyGameStillExists(game) {
+			kept = append(kept, game)
+		} else {
+			pruned = append(pruned, game)
+		}
+	}
+	return kept, pruned
+}
+
+// BeginIncrementalScan records the Steam section for a scan whose games are saved
+// library by library. Unless additive, detected games from libraries that are no
+// longer present are dropped up front, and with prune so are games whose install
+// path is gone.
+func (cu *ConfigUpdater) BeginIncrementalScan(steamPath string, libraries []Library, additive, prune bool) ([]Game, error) {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing config: %w", err)
+	}
+
+	if config.Steam == nil {
+		config.Steam = &SteamConfig{}
+	}
+	if additive {
+		libraries = cu.mergeLibraries(config.Steam.Libraries, libraries)
+	} else {
+		current := make(map[string]bool, len(libraries))
+		for _, lib := range libraries {
+			current[lib.Label] = true
+		}
+		kept := config.DetectedGames[:0]
+		for _, game := range config.DetectedGames {
+			if current[game.Library] {
+				kept = append(kept, game)
+			}
+		}
+		config.DetectedGames = kept
+	}
+	config.Steam.InstallPath = steamPath
+	config.Steam.Libraries = libraries
+	config.Steam.LastScan = time.Now()
+
+	var pruned []Game
+	if prune {
+		config.DetectedGames, pruned = cu.pruneMissingGames(config.DetectedGames)
+	}
+
+	return pruned, cu.saveConfigAtomic(config)
+}
+
+// SaveLibraryGames replaces the detected games of one library and saves right
+// away, so a failure in a later library keeps the results found so far
+func (cu *ConfigUpdater) SaveLibraryGames(library Library, games []Game) error {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load existing config: %w", err)
+	}
+
+	// Carry state over before the filter below reuses the slice
+	keepUserState(config.DetectedGames, games)
+	kept := config.DetectedGames[:0]
+	for _, game := range config.DetectedGames {
+		if game.Library != library.Label {
+			kept = append(kept, game)
+		}
+	}
+	config.DetectedGames = append(kept, games...)
+
+	return cu.saveConfigAtomic(config)
 }
 
 // UpdateGamesSection updates only the games section while preserving other settings
@@ -68,6 +246,7 @@ func (cu *ConfigUpdater) UpdateGamesSection(games []Game) error {
 	}
 
 	// Merge with existing games
+	keepUserState(config.DetectedGames, games)
 	mergedGames := cu.MergeGameLists(config.DetectedGames, games)
 	config.DetectedGames = mergedGames
 
@@ -107,8 +286,8 @@ func (cu *ConfigUpdater) MergeGameLists(existing, scanned []Game) []Game {
 			// This game wasn't found in the scan, check if it still exists
 			if cu.verifyGameStillExists(existingGame) {
 				merged = append(merged, existingGame)
-			} else if verbose {
-				fmt.Printf("🗑️  Removing uninstalled game: %s\n", existingGame.Name)
+			} else {
+				logDebugf("🗑️  Removing uninstalled game: %s\n", existingGame.Name)
 			}
 		}
 	}
@@ -116,6 +295,24 @@ func (cu *ConfigUpdater) MergeGameLists(existing, scanned []Game) []Game {
 	return merged
 }
 
+// mergeLibraries keeps previously known libraries that weren't part of this scan
+func (cu *ConfigUpdater) mergeLibraries(existing, scanned []Library) []Library {
+	seen := make(map[string]bool)
+	for _, lib := range scanned {
+		seen[strings.ToLower(filepath.Clean(lib.Path))] = true
+	}
+
+	merged := make([]Library, 0, len(scanned)+len(existing))
+	merged = append(merged, scanned...)
+	for _, lib := range existing {
+		if !seen[strings.ToLower(filepath.Clean(lib.Path))] {
+			merged = append(merged, lib)
+		}
+	}
+
+	return merged
+}
+
 // verifyGameStillExists checks if a game directory still exists
 func (cu *ConfigUpdater) verifyGameStillExists(game Game) bool {
 	if game.InstallPath == "" {
@@ -127,7 +324,7 @@ func (cu *ConfigUpdater) verifyGameStillExists(game Game) bool {
 }
 
 // convertLegacyGames converts legacy game list to custom games
-func (cu *ConfigUpdater) convertLegacyGames(legacyGames []string) []CustomGame {
+func convertLegacyGames(legacyGames []string) []CustomGame {
 	customGames := make([]CustomGame, 0, len(legacyGames))
 
 	for _, executable := range legacyGames {
@@ -171,6 +368,10 @@ func (cu *ConfigUpdater) loadExistingConfig() (*Config, error) {
 
 // saveConfigAtomic saves the config file atomically using a temporary file
 func (cu *ConfigUpdater) saveConfigAtomic(config *Config) error {
+	if config.DisableLegacyGames && len(config.Games) > 0 {
+		return fmt.Errorf("legacy games list is disabled (disable_legacy_games), use custom_games instead")
+	}
+
 	// Marshal the config to YAML
 	data, err := yaml.Marshal(config)
 	if err != nil {
@@ -181,6 +382,9 @@ func (cu *ConfigUpdater) saveConfigAtomic(config *Config) error {
 	dir := filepath.Dir(cu.configPath)
 	tempFile, err := os.CreateTemp(dir, ".config-*.yaml.tmp")
 	if err != nil {
+		if os.IsPermission(err) {
+			return cu.saveConfigInPlace(data)
+		}
 		return fmt.Errorf("failed to create temporary file: %w", err)
 	}
 
@@ -207,18 +411,136 @@ func (cu *ConfigUpdater) saveConfigAtomic(config *Config) error {
 	}
 	tempFile = nil // Mark as closed
 
+	// Keep the previous version so the change can be undone
+	if err := cu.saveUndoSnapshot(); err != nil && verbose {
+		logWarnf("⚠️ Could not save undo snapshot: %v\n", err)
+	}
+
 	// Atomic rename
 	if err := os.Rename(tempPath, cu.configPath); err != nil {
 		return fmt.Errorf("failed to rename temporary file: %w", err)
 	}
 
-	if verbose {
-		fmt.Printf("💾 Config saved to %s\n", cu.configPath)
+	logDebugf("💾 Config saved to %s\n", cu.configPath)
+
+	return nil
+}
+
+// saveConfigInPlace overwrites the config file directly. It's the fallback for
+// directories we can't create files in, where the file itself may still be writable.
+func (cu *ConfigUpdater) saveConfigInPlace(data []byte) error {
+	logWarnf("⚠️ Config directory %s is not writable, saving without atomic replace\n", filepath.Dir(cu.configPath))
+
+	if err := cu.saveUndoSnapshot(); err != nil && verbose {
+		logWarnf("⚠️ Could not save undo snapshot: %v\n", err)
+	}
+
+	if err := os.WriteFile(cu.configPath, data, 0644); err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("config %s is not writable, run elevated or choose a writable --config: %w", cu.configPath, err)
+		}
+		return fmt.Errorf("failed to write config: %w", err)
 	}
 
+	logDebugf("💾 Config saved to %s\n", cu.configPath)
+
 	return nil
 }
 
+// undoPath returns where the pre-change snapshot is stored
+func (cu *ConfigUpdater) undoPath() string {
+	return cu.configPath + undoSuffix
+}
+
+// saveUndoSnapshot copies the current config file aside before it is replaced,
+// unless this updater only makes background writes
+func (cu *ConfigUpdater) saveUndoSnapshot() error {
+	if cu.skipUndo {
+		return nil
+	}
+	data, err := os.ReadFile(cu.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return os.WriteFile(cu.undoPath(), data, 0644)
+}
+
+// Undo swaps the config with the snapshot taken before the last change, so running
+// it twice redoes the change. It returns the configs before and after the swap.
+func (cu *ConfigUpdater) Undo() (current *Config, restored *Config, err error) {
+	snapshot, err := os.ReadFile(cu.undoPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("nothing to undo")
+		}
+		return nil, nil, fmt.Errorf("failed to read undo snapshot: %w", err)
+	}
+
+	data, err := os.ReadFile(cu.configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	current, restored = &Config{}, &Config{}
+	if err := yaml.Unmarshal(data, current); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := yaml.Unmarshal(snapshot, restored); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse undo snapshot: %w", err)
+	}
+
+	if err := os.WriteFile(cu.configPath, snapshot, 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to restore config: %w", err)
+	}
+	if err := os.WriteFile(cu.undoPath(), data, 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to update undo snapshot: %w", err)
+	}
+
+	return current, restored, nil
+}
+
+// DiffGames lists game names present only in before (removed) or only in after (added)
+func DiffGames(before, after *Config) (added []string, removed []string) {
+	beforeSet := gameNameSet(before)
+	afterSet := gameNameSet(after)
+
+	for name := range afterSet {
+		if !beforeSet[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range beforeSet {
+		if !afterSet[name] {
+			removed = append(removed, name)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// gameNameSet collects every configured game name across all lists
+func gameNameSet(config *Config) map[string]bool {
+	names := make(map[string]bool)
+	for _, game := range config.Games {
+		names[game] = true
+	}
+	for _, games := range config.detectedGameLists() {
+		for _, game := range *games {
+			names[game.Name] = true
+		}
+	}
+	for _, game := range config.CustomGames {
+		names[game.Name] = true
+	}
+	return names
+}
+
 // AddCustomGame adds a custom game to the config
 func (cu *ConfigUpdater) AddCustomGame(name, executable, path string) error {
 	config, err := cu.loadExistingConfig()
@@ -281,6 +603,144 @@ func (cu *ConfigUpdater) RemoveCustomGame(name string) error {
 	return cu.saveConfigAtomic(config)
 }
 
+// RecordGamePath fills in the install path of a detected or custom game that
+// doesn't have one yet. Entries that already carry a path are left alone.
+func (cu *ConfigUpdater) RecordGamePath(executable, path string) error {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	changed := false
+	for _, games := range config.detectedGameLists() {
+		for i := range *games {
+			game := &(*games)[i]
+			if strings.EqualFold(game.Executable, executable) && game.InstallPath == "" {
+				game.InstallPath = path
+				changed = true
+			}
+		}
+	}
+	for i := range config.CustomGames {
+		if strings.EqualFold(config.CustomGames[i].Executable, executable) && config.CustomGames[i].Path == "" {
+			config.CustomGames[i].Path = path
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return cu.saveConfigAtomic(config)
+}
+
+// RecordLastSeen stores when each executable was last seen running, in one write
+func (cu *ConfigUpdater) RecordLastSeen(seen map[string]time.Time) error {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	lookup := make(map[string]time.Time, len(seen))
+	for executable, at := range seen {
+		lookup[strings.ToLower(executable)] = at
+	}
+
+	changed := false
+	for _, games := range config.detectedGameLists() {
+		for i := range *games {
+			game := &(*games)[i]
+			if at, ok := lookup[strings.ToLower(game.Executable)]; ok && at.After(game.LastSeen) {
+				game.LastSeen = at
+				changed = true
+			}
+		}
+	}
+	for i := range config.CustomGames {
+		if at, ok := lookup[strings.ToLower(config.CustomGames[i].Executable)]; ok && at.After(config.CustomGames[i].LastSeen) {
+			config.CustomGames[i].LastSeen = at
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return cu.saveConfigAtomic(config)
+}
+
+// keepUserState copies the per-game settings, last-seen times and enabled flag
+// from existing detected games onto their rescanned entries, which the scanner
+// builds from scratch. Only fields the scanned entry leaves unset are copied.
+func keepUserState(existing, scanned []Game) {
+	previous := make(map[string]Game, len(existing))
+	for _, game := range existing {
+		previous[game.AppID] = game
+	}
+	for i := range scanned {
+		old, ok := previous[scanned[i].AppID]
+		if !ok {
+			continue
+		}
+		game := &scanned[i]
+		if game.PollingRate == 0 {
+			game.PollingRate = old.PollingRate
+		}
+		if game.CloseRate == 0 {
+			game.CloseRate = old.CloseRate
+		}
+		game.Reassert = game.Reassert || old.Reassert
+		if game.ParentExecutable == "" {
+			game.ParentExecutable = old.ParentExecutable
+		}
+		if len(game.ResolutionRates) == 0 {
+			game.ResolutionRates = old.ResolutionRates
+		}
+		if len(game.RefreshRates) == 0 {
+			game.RefreshRates = old.RefreshRates
+		}
+		if game.LastSeen.IsZero() {
+			game.LastSeen = old.LastSeen
+		}
+		if game.Enabled == nil {
+			game.Enabled = old.Enabled
+		}
+	}
+}
+
+// SetGameEnabled turns switching for a detected or custom game on or off by
+// name or executable, keeping the entry and its per-game settings
+func (cu *ConfigUpdater) SetGameEnabled(name string, enabled bool) (string, error) {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var flag *bool
+	if !enabled {
+		flag = &enabled // Enabled is the default, so only disabling is written out
+	}
+
+	for i := range config.CustomGames {
+		game := &config.CustomGames[i]
+		if strings.EqualFold(game.Name, name) || strings.EqualFold(game.Executable, name) {
+			game.Enabled = flag
+			return game.Name, cu.saveConfigAtomic(config)
+		}
+	}
+	for _, detected := range config.detectedGameLists() {
+		for i := range *detected {
+			game := &(*detected)[i]
+			if strings.EqualFold(game.Name, name) || strings.EqualFold(game.Executable, name) {
+				game.Enabled = flag
+				return game.Name, cu.saveConfigAtomic(config)
+			}
+		}
+	}
+
+	return "", fmt.Errorf("game '%s' not found", name)
+}
+
 // GetGameCounts returns counts of different game types
 func (cu *ConfigUpdater) GetGameCounts() (detected int, custom int, legacy int, err error) {
 	config, err := cu.loadExistingConfig()
@@ -288,5 +748,398 @@ func (cu *ConfigUpdater) GetGameCounts() (detected int, custom int, legacy int,
 		return 0, 0, 0, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	return len(config.DetectedGames), len(config.CustomGames), len(config.Games), nil
+	for _, games := range config.detectedGameLists() {
+		detected += len(*games)
+	}
+	return detected, len(config.CustomGames), len(config.Games), nil
+}
+
+// Conflict strategies for ImportCustomGames
+const (
+	conflictKeepLocal      = "keep-local"
+	conflictPreferImported = "prefer-imported"
+	conflictMergeRatesMax  = "merge-rates-max"
+)
+
+// ImportSummary counts what an import did to the custom game list
+type ImportSummary struct {
+	Added   int
+	Updated int
+	Skipped int
+}
+
+// checkConflictStrategy rejects unknown --on-conflict values
+func checkConflictStrategy(strategy string) error {
+	switch strategy {
+	case conflictKeepLocal, conflictPreferImported, conflictMergeRatesMax:
+		return nil
+	}
+	return fmt.Errorf("unknown conflict strategy %q (use %s, %s or %s)", strategy, conflictKeepLocal, conflictPreferImported, conflictMergeRatesMax)
+}
+
+// ImportCustomGames merges custom games from another config file, resolving
+// duplicate executables with the given strategy
+func (cu *ConfigUpdater) ImportCustomGames(source, strategy string) (ImportSummary, error) {
+	var summary ImportSummary
+
+	if err := checkConflictStrategy(strategy); err != nil {
+		return summary, err
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return summary, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	var imported Config
+	if err := yaml.Unmarshal(content, &imported); err != nil {
+		return summary, fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+
+	return cu.mergeCustomGames(imported.CustomGames, strategy, nil)
+}
+
+// mergeCustomGames adds games to the custom game list, resolving duplicate
+// executables with strategy. Executables matched by skip are left alone.
+func (cu *ConfigUpdater) mergeCustomGames(games []CustomGame, strategy string, skip func(*Config, string) bool) (ImportSummary, error) {
+	var summary ImportSummary
+
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return summary, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	index := make(map[string]int, len(config.CustomGames))
+	for i, game := range config.CustomGames {
+		index[strings.ToLower(game.Executable)] = i
+	}
+
+	for _, game := range games {
+		if strings.TrimSpace(game.Executable) == "" || (skip != nil && skip(config, game.Executable)) {
+			summary.Skipped++
+			continue
+		}
+
+		i, exists := index[strings.ToLower(game.Executable)]
+		if !exists {
+			index[strings.ToLower(game.Executable)] = len(config.CustomGames)
+			config.CustomGames = append(config.CustomGames, game)
+			summary.Added++
+			continue
+		}
+
+		local := &config.CustomGames[i]
+		switch strategy {
+		case conflictKeepLocal:
+			summary.Skipped++
+		case conflictPreferImported:
+			*local = game
+			summary.Updated++
+		case conflictMergeRatesMax:
+			updated := false
+			if game.CloseRate > local.CloseRate {
+				local.CloseRate = game.CloseRate
+				updated = true
+			}
+			if game.PollingRate > local.PollingRate {
+				local.PollingRate = game.PollingRate
+				updated = true
+			}
+			if updated {
+				summary.Updated++
+			} else {
+				summary.Skipped++
+			}
+		}
+	}
+
+	if summary.Added == 0 && summary.Updated == 0 {
+		return summary, nil
+	}
+
+	return summary, cu.saveConfigAtomic(config)
+}
+
+// PortableGame is a shareable game entry: how to recognise the game and which
+// rates it wants, without install paths, library labels or Steam AppIDs
+type PortableGame struct {
+	Name             string           `yaml:"name"`
+	Executable       string           `yaml:"executable"`
+	PollingRate      int              `yaml:"polling_rate,omitempty"`
+	CloseRate        int              `yaml:"close_rate,omitempty"`
+	Reassert         bool             `yaml:"reassert,omitempty"`
+	ParentExecutable string           `yaml:"parent_executable,omitempty"`
+	ResolutionRates  []ResolutionRule `yaml:"resolution_rates,omitempty"`
+	RefreshRates     []RefreshRule    `yaml:"refresh_rates,omitempty"`
+}
+
+// PortableGameList is the file written by export-games and read by import-games
+type PortableGameList struct {
+	Games []PortableGame `yaml:"games"`
+}
+
+// portableGames collects every configured game once per executable
+func portableGames(config *Config) []PortableGame {
+	var games []PortableGame
+	seen := make(map[string]bool)
+	add := func(game PortableGame) {
+		key := strings.ToLower(game.Executable)
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		games = append(games, game)
+	}
+
+	for _, game := range config.CustomGames {
+		add(PortableGame{
+			Name:             game.Name,
+			Executable:       game.Executable,
+			PollingRate:      game.PollingRate,
+			CloseRate:        game.CloseRate,
+			Reassert:         game.Reassert,
+			ParentExecutable: game.ParentExecutable,
+			ResolutionRates:  game.ResolutionRates,
+			RefreshRates:     game.RefreshRates,
+		})
+	}
+	for _, detected := range config.detectedGameLists() {
+		for _, game := range *detected {
+			add(PortableGame{
+				Name:             game.Name,
+				Executable:       game.Executable,
+				PollingRate:      game.PollingRate,
+				CloseRate:        game.CloseRate,
+				Reassert:         game.Reassert,
+				ParentExecutable: game.ParentExecutable,
+				ResolutionRates:  game.ResolutionRates,
+				RefreshRates:     game.RefreshRates,
+			})
+		}
+	}
+	for _, executable := range config.Games {
+		add(PortableGame{Name: strings.TrimSuffix(executable, filepath.Ext(executable)), Executable: executable})
+	}
+	return games
+}
+
+// ExportGames writes the configured games to dest as a portable game list
+func (cu *ConfigUpdater) ExportGames(dest string) (int, error) {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	list := PortableGameList{Games: portableGames(config)}
+	data, err := yaml.Marshal(&list)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal games: %w", err)
+	}
+
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return len(list.Games), nil
+}
+
+// ImportGames adds the entries of a portable game list as custom games.
+// Games already found by a scan are skipped so they keep their scanned metadata.
+func (cu *ConfigUpdater) ImportGames(source, strategy string) (ImportSummary, error) {
+	if err := checkConflictStrategy(strategy); err != nil {
+		return ImportSummary{}, err
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return ImportSummary{}, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	var list PortableGameList
+	if err := yaml.Unmarshal(content, &list); err != nil {
+		return ImportSummary{}, fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+
+	games := make([]CustomGame, 0, len(list.Games))
+	for _, game := range list.Games {
+		games = append(games, CustomGame{
+			Name:             game.Name,
+			Executable:       game.Executable,
+			PollingRate:      game.PollingRate,
+			CloseRate:        game.CloseRate,
+			Reassert:         game.Reassert,
+			ParentExecutable: game.ParentExecutable,
+			ResolutionRates:  game.ResolutionRates,
+			RefreshRates:     game.RefreshRates,
+		})
+	}
+
+	detected := func(config *Config, executable string) bool {
+		for _, games := range config.detectedGameLists() {
+			for _, game := range *games {
+				if strings.EqualFold(game.Executable, executable) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return cu.mergeCustomGames(games, strategy, detected)
+}
+
+// GetConfigField returns the YAML rendering of the value at a dotted key such as
+// "game_polling_rate" or "steam.install_path"
+func GetConfigField(config *Config, key string) (string, error) {
+	fields, err := configFields(config)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := lookupField(fields, strings.Split(key, "."))
+	if !ok {
+		return "", fmt.Errorf("unknown or unset key %q", key)
+	}
+
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", key, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetField parses value as YAML, stores it at the dotted key and saves the
+// config if it still validates
+func (cu *ConfigUpdater) SetField(key, value string) error {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fields, err := configFields(config)
+	if err != nil {
+		return err
+	}
+
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return fmt.Errorf("invalid value %q: %w", value, err)
+	}
+
+	if err := storeField(fields, strings.Split(key, "."), parsed); err != nil {
+		return fmt.Errorf("can't set %q: %w", key, err)
+	}
+
+	data, err := yaml.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Decode strictly so typos in the key are reported instead of silently dropped
+	updated := DefaultConfig()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(updated); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	if problems := ValidateConfig(updated); len(problems) > 0 {
+		return problems[0]
+	}
+
+	return cu.saveConfigAtomic(updated)
+}
+
+// configFields converts the config into generic YAML maps for dotted-key access
+func configFields(config *Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	fields := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to read config fields: %w", err)
+	}
+	return fields, nil
+}
+
+// lookupField walks nested maps along the key path
+func lookupField(fields map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := fields[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+
+	nested, isMap := value.(map[string]interface{})
+	if !isMap {
+		return nil, false
+	}
+	return lookupField(nested, path[1:])
+}
+
+// storeField sets the value at the key path, creating intermediate maps as needed
+func storeField(fields map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 1 {
+		fields[path[0]] = value
+		return nil
+	}
+
+	nested, ok := fields[path[0]].(map[string]interface{})
+	if !ok {
+		if _, exists := fields[path[0]]; exists {
+			return fmt.Errorf("%s is not a section", path[0])
+		}
+		nested = make(map[string]interface{})
+		fields[path[0]] = nested
+	}
+	return storeField(nested, path[1:], value)
+}
+
+// GameChange pairs the configured and scanned versions of a game whose details moved
+type GameChange struct {
+	Before Game `json:"before"`
+	After  Game `json:"after"`
+}
+
+// DiffDetectedGames compares configured detected games with a fresh scan by AppID
+func DiffDetectedGames(existing, scanned []Game) (added, removed []Game, changed []GameChange) {
+	current := make(map[string]Game, len(existing))
+	for _, game := range existing {
+		current[game.AppID] = game
+	}
+
+	seen := make(map[string]bool, len(scanned))
+	for _, game := range scanned {
+		seen[game.AppID] = true
+		before, ok := current[game.AppID]
+		if !ok {
+			added = append(added, game)
+			continue
+		}
+		if before.Name != game.Name || !strings.EqualFold(before.Executable, game.Executable) || !strings.EqualFold(before.InstallPath, game.InstallPath) {
+			changed = append(changed, GameChange{Before: before, After: game})
+		}
+	}
+
+	for _, game := range existing {
+		if !seen[game.AppID] {
+			removed = append(removed, game)
+		}
+	}
+
+	return added, removed, changed
+}
+
+// SetActiveProfile records the profile to use from the next start on
+func (cu *ConfigUpdater) SetActiveProfile(name string) error {
+	config, err := cu.loadExistingConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if _, ok := config.Profiles[name]; name != "" && !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	config.ActiveProfile = name
+	return cu.saveConfigAtomic(config)
 }
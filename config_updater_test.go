@@ -0,0 +1,82 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestRescanKeepsPerGameSettings checks the settings a user put on a detected
+// game survive a rescan, which rebuilds every entry from its manifest
+func TestRescanKeepsPerGameSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeTestFile(t, configPath, `default_polling_rate: 1000
+game_polling_rate: 2000
+check_interval: 2s
+detected_games:
+  - name: Counter-Strike 2
+    app_id: "730"
+    executable: cs2.exe
+    install_path: C:\Steam\steamapps\common\Counter-Strike Global Offensive
+    library: main
+    size_mb: 0
+    polling_rate: 8000
+    close_rate: 500
+    reassert: true
+    parent_executable: steam.exe
+    resolution_rates:
+      - max_height: 1080
+        rate: 4000
+    refresh_rates:
+      - min_refresh: 240
+        rate: 8000
+    enabled: false
+detected_epic_games:
+  - name: Fortnite
+    app_id: Fortnite
+    executable: FortniteClient-Win64-Shipping.exe
+    install_path: C:\Epic\Fortnite
+    library: epic
+    size_mb: 0
+    polling_rate: 4000
+`)
+	updater := NewConfigUpdater(configPath)
+
+	scanned := []Game{{Name: "Counter-Strike 2", AppID: "730", Executable: "cs2.exe", Library: "main"}}
+	if _, err := updater.UpdateWithSteamData(`C:\Steam`, []Library{{Path: `C:\Steam`, Label: "main"}}, scanned, false, false); err != nil {
+		t.Fatalf("UpdateWithSteamData: %v", err)
+	}
+	epic := []Game{{Name: "Fortnite", AppID: "Fortnite", Executable: "FortniteClient-Win64-Shipping.exe", Library: epicLibraryLabel}}
+	if _, err := updater.UpdateWithEpicGames(epic); err != nil {
+		t.Fatalf("UpdateWithEpicGames: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(config.DetectedGames) != 1 || len(config.DetectedEpicGames) != 1 {
+		t.Fatalf("got %d Steam and %d Epic games, want 1 each", len(config.DetectedGames), len(config.DetectedEpicGames))
+	}
+
+	cs2 := config.DetectedGames[0]
+	if cs2.PollingRate != 8000 {
+		t.Errorf("polling_rate = %d after a rescan, want 8000", cs2.PollingRate)
+	}
+	if cs2.CloseRate != 500 || !cs2.Reassert || cs2.ParentExecutable != "steam.exe" {
+		t.Errorf("close_rate, reassert, parent_executable = %d, %v, %q; want 500, true, steam.exe", cs2.CloseRate, cs2.Reassert, cs2.ParentExecutable)
+	}
+	if want := []ResolutionRule{{MaxHeight: 1080, Rate: 4000}}; !reflect.DeepEqual(cs2.ResolutionRates, want) {
+		t.Errorf("resolution_rates = %+v, want %+v", cs2.ResolutionRates, want)
+	}
+	if want := []RefreshRule{{MinRefresh: 240, Rate: 8000}}; !reflect.DeepEqual(cs2.RefreshRates, want) {
+		t.Errorf("refresh_rates = %+v, want %+v", cs2.RefreshRates, want)
+	}
+	if gameEnabled(cs2.Enabled) {
+		t.Error("disabled game was enabled by the rescan")
+	}
+
+	if rate := config.DetectedEpicGames[0].PollingRate; rate != 4000 {
+		t.Errorf("Epic polling_rate = %d after a rescan, want 4000", rate)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// epicLibraryLabel tags detected games that came from the Epic Games Launcher
+const epicLibraryLabel = "epic"
+
+// EpicDetector finds games installed through the Epic Games Launcher
+type EpicDetector struct {
+	scanner     *GameScanner
+	manifestDir string
+}
+
+// epicManifest holds the fields used from a launcher .item manifest
+type epicManifest struct {
+	AppName             string `json:"AppName"`
+	DisplayName         string `json:"DisplayName"`
+	InstallLocation     string `json:"InstallLocation"`
+	LaunchExecutable    string `json:"LaunchExecutable"`
+	InstallSize         int64  `json:"InstallSize"`
+	IsIncompleteInstall bool   `json:"bIsIncompleteInstall"`
+	MainGameAppName     string `json:"MainGameAppName"`
+}
+
+// NewEpicDetector creates a detector that reads the launcher's manifests and
+// falls back to the Steam scanner's heuristics when one doesn't name the executable
+func NewEpicDetector() *EpicDetector {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = `C:\ProgramData`
+	}
+	return &EpicDetector{
+		scanner:     NewGameScanner(nil, 1),
+		manifestDir: filepath.Join(programData, "Epic", "EpicGamesLauncher", "Data", "Manifests"),
+	}
+}
+
+// FindGames lists installed Epic games from the launcher's .item manifests
+func (ed *EpicDetector) FindGames() ([]Game, error) {
+	manifests, err := filepath.Glob(filepath.Join(ed.manifestDir, "*.item"))
+	if err != nil {
+		return nil, fmt.Errorf("error finding Epic manifests: %w", err)
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no Epic Games Launcher manifests found in %s", ed.manifestDir)
+	}
+
+	var games []Game
+	for _, manifestPath := range manifests {
+		game, err := ed.parseManifest(manifestPath)
+		if err != nil {
+			logDebugf("⚠️ Skipping Epic manifest %s: %v\n", filepath.Base(manifestPath), err)
+			continue
+		}
+		games = append(games, game)
+		logDebugf("✅ Found Epic game: %s -> %s\n", game.Name, game.Executable)
+	}
+
+	return games, nil
+}
+
+// parseManifest turns one .item manifest into a game, resolving its executable
+func (ed *EpicDetector) parseManifest(manifestPath string) (Game, error) {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return Game{}, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest epicManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return Game{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if manifest.IsIncompleteInstall {
+		return Game{}, fmt.Errorf("%s is not fully installed", manifest.DisplayName)
+	}
+	// DLC and add-ons point at their base game and have no executable of their own
+	if manifest.MainGameAppName != "" && manifest.MainGameAppName != manifest.AppName {
+		return Game{}, fmt.Errorf("%s is an add-on for %s", manifest.DisplayName, manifest.MainGameAppName)
+	}
+
+	installPath := filepath.Clean(manifest.InstallLocation)
+	if manifest.InstallLocation == "" || !ed.scanner.verifyGameInstallation(installPath) {
+		return Game{}, fmt.Errorf("install folder missing for %s (path: %s)", manifest.DisplayName, manifest.InstallLocation)
+	}
+
+	executable := ed.launchExecutable(installPath, manifest.LaunchExecutable)
+	if executable == "" {
+		if executable, err = ed.scanner.FindGameExecutable(installPath, manifest.DisplayName); err != nil {
+			return Game{}, fmt.Errorf("no executable found for %s: %w", manifest.DisplayName, err)
+		}
+	}
+
+	return Game{
+		Name:        manifest.DisplayName,
+		AppID:       "epic:" + manifest.AppName,
+		Executable:  executable,
+		InstallPath: installPath,
+		Library:     epicLibraryLabel,
+		SizeMB:      manifest.InstallSize / (1024 * 1024),
+	}, nil
+}
+
+// launchExecutable returns the manifest's launch executable file name when it
+// exists in the install folder. Some titles launch through a bootstrapper or
+// a non-.exe target, which the scanner heuristics handle better.
+func (ed *EpicDetector) launchExecutable(installPath, launch string) string {
+	if launch == "" || !strings.EqualFold(filepath.Ext(launch), ".exe") {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(installPath, filepath.FromSlash(launch))); err != nil {
+		return ""
+	}
+	return filepath.Base(launch)
+}
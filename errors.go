@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Error codes reported in the --json error envelope
+const (
+	codeConfigError     = "config_error"
+	codeDeviceError     = "device_error"
+	codeInvalidArgument = "invalid_argument"
+	codeSteamNotFound   = "steam_not_found"
+	codeScanError       = "scan_error"
+	codeUsageError      = "usage_error"
+	codeServiceError    = "service_error"
+	codeUnknown         = "error"
+)
+
+// CommandError is an error with a stable code that scripts can match on
+type CommandError struct {
+	Code string
+	Err  error
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// newCommandError wraps err with a code and a message prefix
+func newCommandError(code string, format string, args ...interface{}) error {
+	return &CommandError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// errorEnvelope is what --json prints to stdout when a command fails
+type errorEnvelope struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// runWithErrors adapts an error-returning command so every subcommand reports
+// failures the same way, as text on stderr or as a JSON envelope on stdout
+func runWithErrors(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := run(cmd, args); err != nil {
+			exitWithError(err)
+		}
+	}
+}
+
+// exitWithError reports err and exits with a non-zero status
+func exitWithError(err error) {
+	code := codeUnknown
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		code = cmdErr.Code
+	}
+
+	if jsonOutput {
+		data, _ := json.Marshal(errorEnvelope{Error: err.Error(), Code: code})
+		fmt.Println(string(data))
+	} else {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	}
+
+	os.Exit(1)
+}
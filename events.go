@@ -0,0 +1,105 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// WatchEventType identifies what happened in the watcher
+type WatchEventType string
+
+const (
+	EventGameDetected WatchEventType = "game_detected"
+	EventGameClosed   WatchEventType = "game_closed"
+	EventRateApplied  WatchEventType = "rate_applied"
+	EventError        WatchEventType = "error"
+	EventReconnected  WatchEventType = "reconnected"
+	// The device rate changed without the automator writing it (observe mode)
+	EventRateObserved WatchEventType = "rate_observed"
+	// A scanned game without a resolved executable seems to be running, so
+	// nothing will switch for it
+	EventUnresolvedGame WatchEventType = "unresolved_game"
+)
+
+// What failed, for EventError
+const (
+	failedGameRate    = "game_rate"
+	failedDefaultRate = "default_rate"
+	failedWatcher     = "watcher"
+	failedWatchdog    = "watchdog"
+)
+
+// WatchEvent is emitted by the watcher on Events(). Only the fields relevant
+// to the event type are set.
+type WatchEvent struct {
+	Type   WatchEventType
+	Time   time.Time
+	Game   string // Game name, for game and game-rate events
+	Source string // Where the game came from: steam, custom, legacy or alias
+	Rate   int    // Rate applied or attempted
+	Failed string // What failed, for EventError
+	Path   string // Running executable path, for EventUnresolvedGame
+	Err    error
+}
+
+// watchEventBuffer is how many events can queue before new ones are dropped
+const watchEventBuffer = 64
+
+// Events returns the watcher's event stream. It is closed by Stop.
+func (gw *GameWatcher) Events() <-chan WatchEvent {
+	return gw.events
+}
+
+// emit queues an event without blocking; monitoring never waits on a slow
+// consumer, so events are dropped when the buffer is full
+func (gw *GameWatcher) emit(event WatchEvent) {
+	gw.eventsMu.Lock()
+	defer gw.eventsMu.Unlock()
+	if gw.eventsClosed {
+		return
+	}
+
+	event.Time = time.Now()
+	select {
+	case gw.events <- event:
+	default:
+	}
+}
+
+// eventNotifier shows the notifications for watcher events;
+// NotificationManager implements it with toasts
+type eventNotifier interface {
+	ShowGameDetected(gameName string, pollingRate int)
+	ShowGameClosed(pollingRate int)
+	ShowError(title, message string)
+	ShowUnresolvedGame(gameName, executable string)
+}
+
+// notifyEvents shows toast notifications for watcher events until the stream closes.
+// A nil manager only drains the stream.
+func notifyEvents(events <-chan WatchEvent, notificationManager eventNotifier) {
+	for event := range events {
+		if notificationManager == nil {
+			continue
+		}
+		switch event.Type {
+		case EventGameDetected:
+			notificationManager.ShowGameDetected(event.Game, event.Rate)
+		case EventGameClosed:
+			notificationManager.ShowGameClosed(event.Rate)
+		case EventError:
+			switch event.Failed {
+			case failedGameRate:
+				notificationManager.ShowError("Erro", "Falha ao alterar polling rate para jogo")
+			case failedDefaultRate:
+				notificationManager.ShowError("Erro", "Falha ao alterar polling rate padrão")
+			case failedWatcher:
+				notificationManager.ShowError("Erro", "Falha interna no monitoramento, aplicando polling rate padrão")
+			case failedWatchdog:
+				notificationManager.ShowError("Erro", "Monitoramento travou e foi reiniciado")
+			}
+		case EventUnresolvedGame:
+			notificationManager.ShowUnresolvedGame(event.Game, filepath.Base(event.Path))
+		}
+	}
+}
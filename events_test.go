@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fakeNotifier records the notifications it was asked to show
+type fakeNotifier struct {
+	shown []string
+}
+
+func (n *fakeNotifier) ShowGameDetected(gameName string, pollingRate int) {
+	n.shown = append(n.shown, fmt.Sprintf("detected %s %d", gameName, pollingRate))
+}
+
+func (n *fakeNotifier) ShowGameClosed(pollingRate int) {
+	n.shown = append(n.shown, fmt.Sprintf("closed %d", pollingRate))
+}
+
+func (n *fakeNotifier) ShowError(title, message string) {
+	n.shown = append(n.shown, "error "+message)
+}
+
+func (n *fakeNotifier) ShowUnresolvedGame(gameName, executable string) {
+	n.shown = append(n.shown, fmt.Sprintf("unresolved %s %s", gameName, executable))
+}
+
+// TestNotifyEvents checks which watcher events become notifications
+func TestNotifyEvents(t *testing.T) {
+	events := make(chan WatchEvent, 8)
+	events <- WatchEvent{Type: EventGameDetected, Game: "Celeste", Rate: 2000}
+	events <- WatchEvent{Type: EventRateApplied, Rate: 2000}
+	events <- WatchEvent{Type: EventError, Failed: failedGameRate}
+	events <- WatchEvent{Type: EventError, Failed: failedWatchdog}
+	events <- WatchEvent{Type: EventUnresolvedGame, Game: "Hades", Path: `C:\Games\Hades\Hades.exe`}
+	events <- WatchEvent{Type: EventReconnected}
+	events <- WatchEvent{Type: EventGameClosed, Rate: 1000}
+	close(events)
+
+	notifier := &fakeNotifier{}
+	notifyEvents(events, notifier)
+
+	want := []string{
+		"detected Celeste 2000",
+		"error Falha ao alterar polling rate para jogo",
+		"error Monitoramento travou e foi reiniciado",
+		"unresolved Hades Hades.exe",
+		"closed 1000",
+	}
+	if !reflect.DeepEqual(notifier.shown, want) {
+		t.Errorf("notifications = %q, want %q", notifier.shown, want)
+	}
+}
+
+// TestNotifyEventsWithoutNotifier checks a nil notifier still drains the stream
+func TestNotifyEventsWithoutNotifier(t *testing.T) {
+	events := make(chan WatchEvent, 2)
+	events <- WatchEvent{Type: EventGameDetected, Game: "Celeste", Rate: 2000}
+	events <- WatchEvent{Type: EventGameClosed, Rate: 1000}
+	close(events)
+
+	notifyEvents(events, nil)
+
+	if len(events) != 0 {
+		t.Errorf("%d events left in the stream", len(events))
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// webhookTimeout bounds how long a single webhook delivery may take
+const webhookTimeout = 10 * time.Second
+
+// switchPayload is the JSON body posted to the on_switch_webhook URL
+type switchPayload struct {
+	Rate    int    `json:"rate"`
+	Game    string `json:"game"`
+	Content string `json:"content"` // Lets Discord webhooks display the event as-is
+}
+
+// runSwitchHooks fires the configured command and webhook for a rate switch.
+// Both run in the background so a slow or failing hook never delays the switch.
+func runSwitchHooks(config *Config, rate int, game string) {
+	if config.OnSwitchCommand != "" {
+		go runSwitchCommand(config.OnSwitchCommand, rate, game)
+	}
+	if config.OnSwitchWebhook != "" {
+		go postSwitchWebhook(config.OnSwitchWebhook, rate, game)
+	}
+}
+
+// runSwitchCommand expands the {rate} and {game} placeholders and runs the command through cmd.exe
+func runSwitchCommand(template string, rate int, game string) {
+	command := strings.NewReplacer("{rate}", strconv.Itoa(rate), "{game}", game).Replace(template)
+
+	cmd := exec.Command("cmd", "/C", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logWarnf("⚠️ on_switch_command failed: %v\n", err)
+		if len(output) > 0 {
+			logDebugf("   %s\n", strings.TrimSpace(string(output)))
+		}
+		return
+	}
+
+	logDebugf("🔗 Ran on_switch_command: %s\n", command)
+}
+
+// postSwitchWebhook posts the switch event as JSON to the configured URL
+func postSwitchWebhook(url string, rate int, game string) {
+	content := fmt.Sprintf("Polling rate switched to %dHz", rate)
+	if game != "" {
+		content = fmt.Sprintf("%s (%s)", content, game)
+	}
+
+	body, err := json.Marshal(switchPayload{Rate: rate, Game: game, Content: content})
+	if err != nil {
+		logWarnf("⚠️ on_switch_webhook failed: %v\n", err)
+		return
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logWarnf("⚠️ on_switch_webhook failed: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		logWarnf("⚠️ on_switch_webhook returned %s\n", resp.Status)
+		return
+	}
+
+	logDebugf("🔗 Posted switch event to webhook (%s)\n", resp.Status)
+}
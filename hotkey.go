@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+)
+
+const (
+	modAlt      = 0x0001
+	modControl  = 0x0002
+	modShift    = 0x0004
+	modWin      = 0x0008
+	modNoRepeat = 0x4000
+
+	wmHotkey = 0x0312
+	wmQuit   = 0x0012
+
+	pauseHotkeyID = 1
+)
+
+type winMsg struct {
+	Hwnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      struct{ X, Y int32 }
+}
+
+// HotkeyListener owns a global hotkey registration and the message loop serving it
+type HotkeyListener struct {
+	threadID uint32
+	done     chan struct{}
+}
+
+// parseHotkey turns a combo like "Ctrl+Alt+P" or "Shift+F9" into RegisterHotKey arguments
+func parseHotkey(combo string) (modifiers uint32, vk uint32, err error) {
+	parts := strings.Split(combo, "+")
+	for i, part := range parts {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if i < len(parts)-1 {
+			switch part {
+			case "CTRL", "CONTROL":
+				modifiers |= modControl
+			case "ALT":
+				modifiers |= modAlt
+			case "SHIFT":
+				modifiers |= modShift
+			case "WIN":
+				modifiers |= modWin
+			default:
+				return 0, 0, fmt.Errorf("unknown modifier %q in hotkey %q", part, combo)
+			}
+			continue
+		}
+
+		switch {
+		case len(part) == 1 && (part[0] >= 'A' && part[0] <= 'Z' || part[0] >= '0' && part[0] <= '9'):
+			vk = uint32(part[0])
+		case len(part) >= 2 && part[0] == 'F':
+			var n int
+			if _, err := fmt.Sscanf(part[1:], "%d", &n); err != nil || n < 1 || n > 24 {
+				return 0, 0, fmt.Errorf("unknown key %q in hotkey %q", part, combo)
+			}
+			vk = 0x70 + uint32(n-1) // VK_F1..VK_F24
+		default:
+			return 0, 0, fmt.Errorf("unknown key %q in hotkey %q", part, combo)
+		}
+	}
+
+	if modifiers == 0 {
+		return 0, 0, fmt.Errorf("hotkey %q needs at least one modifier", combo)
+	}
+	return modifiers | modNoRepeat, vk, nil
+}
+
+// startHotkey registers the combo and calls onPress each time it's pressed.
+// RegisterHotKey ties the hotkey to the calling thread, so the registration and
+// message loop live on one locked OS thread.
+func startHotkey(combo string, onPress func()) (*HotkeyListener, error) {
+	modifiers, vk, err := parseHotkey(combo)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := &HotkeyListener{done: make(chan struct{})}
+	ready := make(chan error, 1)
+
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer close(listener.done)
+
+		listener.threadID = windows.GetCurrentThreadId()
+		if ret, _, err := procRegisterHotKey.Call(0, pauseHotkeyID, uintptr(modifiers), uintptr(vk)); ret == 0 {
+			ready <- fmt.Errorf("failed to register hotkey %s: %v", combo, err)
+			return
+		}
+		defer procUnregisterHotKey.Call(0, pauseHotkeyID)
+		ready <- nil
+
+		var msg winMsg
+		for {
+			// GetMessage returns 0 for WM_QUIT and -1 on error
+			ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if int32(ret) <= 0 {
+				return
+			}
+			if msg.Message == wmHotkey && msg.WParam == pauseHotkeyID {
+				onPress()
+			}
+		}
+	}()
+
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Stop ends the message loop, which unregisters the hotkey
+func (hl *HotkeyListener) Stop() {
+	procPostThreadMessageW.Call(uintptr(hl.threadID), wmQuit, 0, 0)
+	<-hl.done
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Learner watches the foreground window and suggests unknown apps that stay
+// in front long enough as custom games
+type Learner struct {
+	config    *Config
+	updater   *ConfigUpdater
+	threshold time.Duration
+	autoAdd   bool
+	anyWindow bool
+
+	candidate string    // Executable currently in front
+	since     time.Time // When candidate came to the front
+	handled   map[string]bool
+	input     *bufio.Reader
+}
+
+// NewLearner creates a learner that saves accepted games through the updater
+func NewLearner(config *Config, updater *ConfigUpdater, threshold time.Duration, autoAdd, anyWindow bool) *Learner {
+	return &Learner{
+		config:    config,
+		updater:   updater,
+		threshold: threshold,
+		autoAdd:   autoAdd,
+		anyWindow: anyWindow,
+		handled:   make(map[string]bool),
+		input:     bufio.NewReader(os.Stdin),
+	}
+}
+
+// Check samples the foreground app once and offers it when it has been in
+// front for the threshold
+func (l *Learner) Check() {
+	path, fullscreen, err := foregroundApp()
+	if err != nil {
+		logDebugf("⚠️ %v\n", err)
+		l.candidate = ""
+		return
+	}
+
+	executable := filepath.Base(path)
+
+	// Overlays and our own toasts briefly take focus; they don't end the candidate's streak
+	if foregroundIgnored(l.config, executable) {
+		return
+	}
+
+	key := matchKey(l.config, executable)
+	if (!fullscreen && !l.anyWindow) || l.handled[key] || l.isKnown(key) {
+		l.candidate = ""
+		return
+	}
+
+	if l.candidate != key {
+		l.candidate = key
+		l.since = time.Now()
+		logDebugf("👀 Watching %s\n", executable)
+		return
+	}
+
+	if time.Since(l.since) < l.threshold {
+		return
+	}
+
+	l.handled[key] = true
+	l.offer(executable, filepath.Dir(path))
+}
+
+// isKnown reports whether the executable is already configured
+func (l *Learner) isKnown(key string) bool {
+	for _, game := range configuredGames(l.config) {
+		if matchKey(l.config, game.Executable) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// offer adds the app as a custom game, asking first unless auto-add is on
+func (l *Learner) offer(executable, dir string) {
+	name := strings.TrimSuffix(executable, filepath.Ext(executable))
+	fmt.Printf("🆕 %s has been in front for %s (%s)\n", executable, l.threshold, dir)
+
+	if !l.autoAdd {
+		fmt.Printf("   Add it as a custom game? [y/N] ")
+		answer, _ := l.input.ReadString('\n')
+		if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+			return
+		}
+	}
+
+	if err := l.updater.AddCustomGame(name, executable, dir); err != nil {
+		logErrorf("❌ Failed to add %s: %v\n", executable, err)
+		return
+	}
+
+	l.config.CustomGames = append(l.config.CustomGames, CustomGame{Name: name, Executable: executable, Path: dir})
+	logInfof("✅ Added custom game: %s (%s)\n", name, executable)
+}
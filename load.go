@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// LoadTrigger switches to the game rate while the system stays busy, as an
+// alternative to matching game processes by name
+type LoadTrigger struct {
+	CPUPercent float64       `yaml:"cpu_percent,omitempty"` // CPU utilization that counts as busy (0 disables)
+	GPUPercent float64       `yaml:"gpu_percent,omitempty"` // GPU 3D engine utilization that counts as busy (0 disables)
+	Sustain    time.Duration `yaml:"sustain,omitempty"`     // How long load must stay above or below before switching (default 10s)
+}
+
+// defaultLoadSustain is used when the load trigger doesn't set sustain
+const defaultLoadSustain = 10 * time.Second
+
+// loadGameName is how the load trigger shows up among running games
+const loadGameName = "System load"
+
+var (
+	procGetSystemTimes = kernel32.NewProc("GetSystemTimes")
+
+	pdhDLL                      = syscall.NewLazyDLL("pdh.dll")
+	pdhOpenQuery                = pdhDLL.NewProc("PdhOpenQueryW")
+	pdhAddEnglishCounter        = pdhDLL.NewProc("PdhAddEnglishCounterW")
+	pdhCollectQueryData         = pdhDLL.NewProc("PdhCollectQueryData")
+	pdhGetFormattedCounterArray = pdhDLL.NewProc("PdhGetFormattedCounterArrayW")
+	pdhCloseQuery               = pdhDLL.NewProc("PdhCloseQuery")
+)
+
+const (
+	pdhFmtDouble = 0x00000200
+	pdhMoreData  = 0x800007D2
+
+	// gpuCounterPath sums the 3D engine of every process, as Task Manager does
+	gpuCounterPath = `\GPU Engine(*engtype_3D)\Utilization Percentage`
+)
+
+// validateLoadTrigger checks the load_trigger section
+func validateLoadTrigger(trigger *LoadTrigger) []error {
+	if trigger == nil {
+		return nil
+	}
+
+	var problems []error
+	if trigger.CPUPercent == 0 && trigger.GPUPercent == 0 {
+		problems = append(problems, fmt.Errorf("load_trigger: set cpu_percent and/or gpu_percent"))
+	}
+	if trigger.CPUPercent < 0 || trigger.CPUPercent > 100 {
+		problems = append(problems, fmt.Errorf("load_trigger.cpu_percent: must be between 0 and 100"))
+	}
+	if trigger.GPUPercent < 0 || trigger.GPUPercent > 100 {
+		problems = append(problems, fmt.Errorf("load_trigger.gpu_percent: must be between 0 and 100"))
+	}
+	if trigger.Sustain < 0 {
+		problems = append(problems, fmt.Errorf("load_trigger.sustain: must not be negative"))
+	}
+	return problems
+}
+
+// cpuSampler measures overall CPU utilization between two calls
+type cpuSampler struct {
+	idle, total uint64
+}
+
+// sample returns the CPU utilization since the previous sample, in percent.
+// The first call only primes the counters and reports 0.
+func (c *cpuSampler) sample() (float64, error) {
+	var idle, kernel, user syscall.Filetime
+	ret, _, err := procGetSystemTimes.Call(
+		uintptr(unsafe.Pointer(&idle)),
+		uintptr(unsafe.Pointer(&kernel)),
+		uintptr(unsafe.Pointer(&user)),
+	)
+	if ret == 0 {
+		return 0, fmt.Errorf("GetSystemTimes failed: %w", err)
+	}
+
+	idleTicks := filetimeTicks(idle)
+	totalTicks := filetimeTicks(kernel) + filetimeTicks(user) // Kernel time includes idle time
+	idleDelta, totalDelta := idleTicks-c.idle, totalTicks-c.total
+	primed := c.total != 0
+	c.idle, c.total = idleTicks, totalTicks
+
+	if !primed || totalDelta == 0 {
+		return 0, nil
+	}
+	return 100 * float64(totalDelta-idleDelta) / float64(totalDelta), nil
+}
+
+func filetimeTicks(ft syscall.Filetime) uint64 {
+	return uint64(ft.HighDateTime)<<32 | uint64(ft.LowDateTime)
+}
+
+// gpuCounter reads GPU 3D engine utilization through a performance counter
+type gpuCounter struct {
+	query   uintptr
+	counter uintptr
+}
+
+// pdhCounterValueItem is PDH_FMT_COUNTERVALUE_ITEM_W with a double value (64-bit layout)
+type pdhCounterValueItem struct {
+	Name   *uint16
+	Status uint32
+	_      uint32
+	Value  float64
+}
+
+// openGPUCounter sets up the GPU utilization counter. Not every driver exposes it.
+func openGPUCounter() (*gpuCounter, error) {
+	var g gpuCounter
+	if ret, _, _ := pdhOpenQuery.Call(0, 0, uintptr(unsafe.Pointer(&g.query))); ret != 0 {
+		return nil, fmt.Errorf("PdhOpenQuery failed: 0x%08X", ret)
+	}
+
+	path, err := syscall.UTF16PtrFromString(gpuCounterPath)
+	if err != nil {
+		g.close()
+		return nil, err
+	}
+	if ret, _, _ := pdhAddEnglishCounter.Call(g.query, uintptr(unsafe.Pointer(path)), 0, uintptr(unsafe.Pointer(&g.counter))); ret != 0 {
+		g.close()
+		return nil, fmt.Errorf("GPU utilization counter not available: 0x%08X", ret)
+	}
+
+	// Rates need two collections; prime the first one now
+	pdhCollectQueryData.Call(g.query)
+	return &g, nil
+}
+
+// sample returns the summed 3D engine utilization since the previous sample, capped at 100
+func (g *gpuCounter) sample() (float64, error) {
+	if ret, _, _ := pdhCollectQueryData.Call(g.query); ret != 0 {
+		return 0, fmt.Errorf("PdhCollectQueryData failed: 0x%08X", ret)
+	}
+
+	var size, count uint32
+	ret, _, _ := pdhGetFormattedCounterArray.Call(g.counter, pdhFmtDouble, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), 0)
+	if ret != pdhMoreData || size == 0 {
+		return 0, nil // No GPU engines running right now
+	}
+
+	buffer := make([]byte, size)
+	ret, _, _ = pdhGetFormattedCounterArray.Call(g.counter, pdhFmtDouble, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), uintptr(unsafe.Pointer(&buffer[0])))
+	if ret != 0 {
+		return 0, fmt.Errorf("PdhGetFormattedCounterArray failed: 0x%08X", ret)
+	}
+
+	items := unsafe.Slice((*pdhCounterValueItem)(unsafe.Pointer(&buffer[0])), count)
+	total := 0.0
+	for _, item := range items {
+		if item.Status == 0 {
+			total += item.Value
+		}
+	}
+	return min(total, 100), nil
+}
+
+func (g *gpuCounter) close() {
+	if g.query != 0 {
+		pdhCloseQuery.Call(g.query)
+		g.query = 0
+	}
+}
+
+// loadMonitor decides whether the system counts as busy, only flipping after
+// the readings have disagreed with the current state for the sustain period
+type loadMonitor struct {
+	trigger  LoadTrigger
+	cpu      cpuSampler
+	gpu      *gpuCounter
+	busy     bool
+	changing time.Time // When readings started disagreeing with busy
+}
+
+func newLoadMonitor(trigger LoadTrigger) *loadMonitor {
+	if trigger.Sustain == 0 {
+		trigger.Sustain = defaultLoadSustain
+	}
+	lm := &loadMonitor{trigger: trigger}
+	lm.cpu.sample()
+
+	if trigger.GPUPercent > 0 {
+		gpu, err := openGPUCounter()
+		if err != nil {
+			logWarnf("⚠️ GPU load can't be read, using CPU load only: %v\n", err)
+		} else {
+			lm.gpu = gpu
+		}
+	}
+	return lm
+}
+
+// update takes a new sample and reports whether the system is busy
+func (lm *loadMonitor) update(now time.Time) bool {
+	cpu, err := lm.cpu.sample()
+	if err != nil {
+		logDebugf("⚠️ %v\n", err)
+		return lm.busy
+	}
+	gpu := 0.0
+	if lm.gpu != nil {
+		if gpu, err = lm.gpu.sample(); err != nil {
+			logDebugf("⚠️ %v\n", err)
+		}
+	}
+
+	over := (lm.trigger.CPUPercent > 0 && cpu >= lm.trigger.CPUPercent) ||
+		(lm.gpu != nil && gpu >= lm.trigger.GPUPercent)
+	logDebugf("📈 Load: CPU %.0f%%, GPU %.0f%%\n", cpu, gpu)
+
+	if over == lm.busy {
+		lm.changing = time.Time{}
+		return lm.busy
+	}
+	if lm.changing.IsZero() {
+		lm.changing = now
+	}
+	if now.Sub(lm.changing) >= lm.trigger.Sustain {
+		lm.busy = over
+		lm.changing = time.Time{}
+		if over {
+			logInfof("📈 System busy for %s (CPU %.0f%%, GPU %.0f%%)\n", lm.trigger.Sustain, cpu, gpu)
+		} else {
+			logInfof("📉 System idle for %s (CPU %.0f%%, GPU %.0f%%)\n", lm.trigger.Sustain, cpu, gpu)
+		}
+	}
+	return lm.busy
+}
+
+func (lm *loadMonitor) close() {
+	if lm.gpu != nil {
+		lm.gpu.close()
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel orders log messages by severity
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var logLevelNames = map[string]LogLevel{
+	"debug": LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarn,
+	"error": LevelError,
+}
+
+func (l LogLevel) String() string {
+	for name, level := range logLevelNames {
+		if level == l {
+			return strings.ToUpper(name)
+		}
+	}
+	return "UNKNOWN"
+}
+
+var (
+	logMu       sync.Mutex
+	logLevel              = LevelInfo
+	logOutput   io.Writer = os.Stdout
+	logFileSink bool      // Prefix lines with a timestamp and level when writing to --log-file
+	logEvents   eventSink // Set when running as a service; replaces logOutput
+)
+
+// eventSink is the Windows event log, as opened by the service
+type eventSink interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+}
+
+// eventID is the event ID used for every message written to the event log
+const eventID = 1
+
+// parseLogLevel converts a --log-level value
+func parseLogLevel(name string) (LogLevel, error) {
+	level, ok := logLevelNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("invalid log level %q (use debug, info, warn or error)", name)
+	}
+	return level, nil
+}
+
+// initLogging applies --log-level and --log-file. --verbose is kept as an
+// alias for debug, as is --verbose-hid since its dumps are logged at debug
+// level, and debug level turns verbose on for code that checks it.
+func initLogging() error {
+	level, err := parseLogLevel(logLevelFlag)
+	if err != nil {
+		return newCommandError(codeInvalidArgument, "%w", err)
+	}
+	if (verbose || verboseHID) && level > LevelDebug {
+		level = LevelDebug
+	}
+	logLevel = level
+	verbose = level == LevelDebug
+
+	if logFile != "" {
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return newCommandError(codeInvalidArgument, "failed to open log file: %w", err)
+		}
+		logOutput = file
+		logFileSink = true
+	}
+	return nil
+}
+
+// closeLogging flushes and closes the --log-file, sending any later output to
+// stdout. It does nothing when logging to stdout.
+func closeLogging() {
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	file, ok := logOutput.(*os.File)
+	if !ok || !logFileSink {
+		return
+	}
+	file.Sync()
+	file.Close()
+	logOutput = os.Stdout
+	logFileSink = false
+}
+
+// logf writes a message at the given level if it passes the configured level
+func logf(level LogLevel, format string, args ...interface{}) {
+	if level < logLevel {
+		return
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	message := fmt.Sprintf(format, args...)
+	if logEvents != nil {
+		message = strings.TrimSpace(message)
+		switch level {
+		case LevelError:
+			logEvents.Error(eventID, message)
+		case LevelWarn:
+			logEvents.Warning(eventID, message)
+		default:
+			logEvents.Info(eventID, message)
+		}
+		return
+	}
+	if logFileSink {
+		message = fmt.Sprintf("%s %-5s %s", time.Now().Format("2006-01-02 15:04:05"), level, message)
+	}
+	fmt.Fprint(logOutput, message)
+}
+
+func logDebugf(format string, args ...interface{}) { logf(LevelDebug, format, args...) }
+func logInfof(format string, args ...interface{})  { logf(LevelInfo, format, args...) }
+func logWarnf(format string, args ...interface{})  { logf(LevelWarn, format, args...) }
+func logErrorf(format string, args ...interface{}) { logf(LevelError, format, args...) }
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
@@ -20,66 +23,353 @@ var (
 	gameName     string
 	gameExe      string
 	gamePath     string
+	watchConfig  bool
+	jsonOutput   bool
+	shareMode    string
+	since        time.Duration
+	noConfig     bool
+	flagDefault  int
+	flagGameRate int
+	flagGames    []string
+	mergeScan    bool
+	scanOutput   string
+	probeRates   bool
+	unmounted    bool
+	temporary    time.Duration
+	onConflict   string
+	excludes     []string
+	scanThreads  int
+	scanOnce     bool
+	incremental  bool
+	learnFor     time.Duration
+	learnAutoAdd bool
+	learnAnyWin  bool
+	verboseHID   bool
+	procFilter   string
+	minSizeMB    int64
+	keepUnknown  bool
+	scanUbisoft  bool
+	slotFlag     string
+	allProcesses bool
+	quiet        bool
+	installedMB  int64
+	logLevelFlag string
+	logFile      string
+	hidInterface int
+	anyInterface bool
+	simDuration  time.Duration
+	observe      bool
+	pruneScan    bool
+	deviceFlag   string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "lamzu-automator",
 	Short: "LAMZU Mouse Polling Rate Auto-Switch",
 	Long:  "Automatically adjusts LAMZU mouse polling rate based on running applications",
-	Run:   runAutomator,
+	Run:   runWithErrors(runAutomator),
 }
 
 var setCmd = &cobra.Command{
 	Use:   "set [rate]",
 	Short: "Set polling rate manually",
 	Args:  cobra.ExactArgs(1),
-	Run:   runSetRate,
+	Run:   runWithErrors(runSetRate),
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Read the current polling rate from the mouse",
+	Args:  cobra.NoArgs,
+	Run:   runWithErrors(runGetRate),
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available polling rates",
-	Run:   runListRates,
+	Run:   runWithErrors(runListRates),
 }
 
 var debugCmd = &cobra.Command{
 	Use:   "debug",
 	Short: "Debug and list LAMZU devices",
-	Run:   runDebug,
+	Run:   runWithErrors(runDebug),
 }
 
 var scanSteamCmd = &cobra.Command{
 	Use:   "scan-steam",
 	Short: "Scan for Steam games and update config",
-	Run:   runScanSteam,
+	Run:   runWithErrors(runScanSteam),
+}
+
+var scanEpicCmd = &cobra.Command{
+	Use:   "scan-epic",
+	Short: "Scan for Epic Games Launcher games and update config",
+	Args:  cobra.NoArgs,
+	Run:   runWithErrors(runScanEpic),
 }
 
 var addGameCmd = &cobra.Command{
 	Use:   "add-game",
 	Short: "Add a custom game manually",
-	Run:   runAddGame,
+	Run:   runWithErrors(runAddGame),
 }
 
 var removeGameCmd = &cobra.Command{
 	Use:   "remove-game",
 	Short: "Remove a custom game",
-	Run:   runRemoveGame,
+	Run:   runWithErrors(runRemoveGame),
 }
 
 var listGamesCmd = &cobra.Command{
 	Use:   "list-games",
 	Short: "List all configured games",
-	Run:   runListGames,
+	Run:   runWithErrors(runListGames),
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the config file",
+	Run:   runWithErrors(runValidate),
+}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [powershell|bash|zsh|fish]",
+	Short: "Generate shell completion script",
+	Long: `Generate a shell completion script for lamzu-automator.
+
+PowerShell (add to your $PROFILE to load on startup):
+  lamzu-automator.exe completion powershell | Out-String | Invoke-Expression
+
+Bash:
+  source <(lamzu-automator completion bash)`,
+	ValidArgs: []string{"powershell", "bash", "zsh", "fish"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Run:       runWithErrors(runCompletion),
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last config change made by this tool",
+	Run:   runWithErrors(runUndo),
+}
+
+var listLibrariesCmd = &cobra.Command{
+	Use:   "list-libraries",
+	Short: "List known Steam libraries and their status",
+	Run:   runWithErrors(runListLibraries),
+}
+
+var diffScanCmd = &cobra.Command{
+	Use:   "diff-scan",
+	Short: "Show what a Steam scan would add, remove or change without saving",
+	Run:   runWithErrors(runDiffScan),
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage rate profiles",
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Make a profile active from the next start (\"\" for top-level rates)",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWithErrors(runProfileUse),
+}
+
+var processesCmd = &cobra.Command{
+	Use:   "processes",
+	Short: "List running processes as the watcher's matcher sees them",
+	Run:   runWithErrors(runProcesses),
+}
+
+var learnCmd = &cobra.Command{
+	Use:   "learn",
+	Short: "Suggest apps you keep in fullscreen as custom games",
+	Run:   runWithErrors(runLearn),
+}
+
+var scanInstalledCmd = &cobra.Command{
+	Use:   "scan-installed",
+	Short: "Offer standalone games found in the Windows uninstall list",
+	Run:   runWithErrors(runScanInstalled),
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read or change individual config values",
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Print a config value (dotted keys, e.g. steam.install_path)",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWithErrors(runConfigGet),
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Validate and save a config value (dotted keys, e.g. game_polling_rate)",
+	Args:  cobra.ExactArgs(2),
+	Run:   runWithErrors(runConfigSet),
+}
+
+var importConfigCmd = &cobra.Command{
+	Use:   "import-config [file]",
+	Short: "Import custom games from another config file",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWithErrors(runImportConfig),
+}
+
+var enableGameCmd = &cobra.Command{
+	Use:   "enable-game [name]",
+	Short: "Let a disabled game trigger rate switches again",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWithErrors(runEnableGame),
+}
+
+var disableGameCmd = &cobra.Command{
+	Use:   "disable-game [name]",
+	Short: "Stop a game from triggering rate switches without removing it",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWithErrors(runDisableGame),
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Pretend a configured game runs for a while to test switching end-to-end",
+	Run:   runWithErrors(runSimulate),
+}
+
+var exportGamesCmd = &cobra.Command{
+	Use:   "export-games [file]",
+	Short: "Export configured games as a shareable list without paths or AppIDs",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWithErrors(runExportGames),
+}
+
+var importGamesCmd = &cobra.Command{
+	Use:   "import-games [file]",
+	Short: "Add the games from an export-games file as custom games",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWithErrors(runImportGames),
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show connected device details and current polling rate",
+	Run:   runWithErrors(runInfo),
+}
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Interactive setup: detect the mouse, pick rates, scan Steam and enable autostart",
+	Run:   runWithErrors(runSetup),
+}
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Install or remove the Windows service",
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Register as a Windows service that starts with the system (run as Administrator)",
+	Args:  cobra.NoArgs,
+	Run:   runWithErrors(runServiceInstall),
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop and remove the Windows service (run as Administrator)",
+	Args:  cobra.NoArgs,
+	Run:   runWithErrors(runServiceUninstall),
+}
+
+var serviceRunCmd = &cobra.Command{
+	Use:    "run",
+	Short:  "Entry point used by the Windows service",
+	Args:   cobra.NoArgs,
+	Hidden: true,
+	Run:    runWithErrors(runServiceRun),
+}
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Show the running watcher's switch counters and current state",
+	Args:  cobra.NoArgs,
+	Run:   runWithErrors(runMetrics),
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show build version and supported devices",
+	Run:   runWithErrors(runVersion),
 }
 
 func init() {
+	rootCmd.PersistentPreRun = runWithErrors(func(cmd *cobra.Command, args []string) error {
+		return initLogging()
+	})
+
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "config.yaml", "config file")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&verboseHID, "verbose-hid", false, "dump every HID report sent and received in hex (implies --log-level debug)")
+	rootCmd.PersistentFlags().StringVar(&logLevelFlag, "log-level", "info", "minimum log level: debug, info, warn or error (--verbose implies debug)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "append log output to this file with timestamps instead of stdout")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress informational notices (e.g. default config created)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print JSON where supported and report errors as a JSON envelope on stdout")
+	rootCmd.PersistentFlags().StringVar(&shareMode, "share-mode", "shared", "device share mode: shared, read or exclusive")
+	rootCmd.PersistentFlags().BoolVar(&allProcesses, "include-system-processes", false, "match against services and system processes too (overrides include_system_processes)")
+	rootCmd.PersistentFlags().IntVar(&hidInterface, "interface", INTERFACE_NUMBER, "HID interface number (mi_XX) to send commands to")
+	rootCmd.PersistentFlags().BoolVar(&anyInterface, "all-interfaces", false, "fall back to any LAMZU interface, even ones whose capabilities can't be read")
+	rootCmd.PersistentFlags().StringVar(&deviceFlag, "device", "", "only control the LAMZU device at this path (listed with -v); default: every connected LAMZU mouse")
+	rootCmd.PersistentFlags().StringVar(&slotFlag, "slot", "", "onboard profile slot to write rates to: a number or auto (overrides device.slot)")
+
+	// Portable mode flags
+	rootCmd.PersistentFlags().BoolVar(&noConfig, "no-config", false, "run purely from flags without reading or writing a config file")
+	rootCmd.PersistentFlags().IntVar(&flagDefault, "default-rate", 1000, "default polling rate (with --no-config)")
+	rootCmd.PersistentFlags().IntVar(&flagGameRate, "game-rate", 2000, "game polling rate (with --no-config)")
+	rootCmd.PersistentFlags().StringSliceVar(&flagGames, "game", nil, "game executable to monitor, repeatable (with --no-config)")
 	rootCmd.Flags().BoolVarP(&daemon, "daemon", "d", false, "run as daemon")
+	rootCmd.Flags().BoolVar(&observe, "observe", false, "only read and log the device polling rate (e.g. set by the official software), never change it")
+
+	// Import command flags
+	importConfigCmd.Flags().StringVar(&onConflict, "on-conflict", conflictKeepLocal, "how to handle duplicate executables: keep-local, prefer-imported, merge-rates-max")
+	importGamesCmd.Flags().StringVar(&onConflict, "on-conflict", conflictKeepLocal, "how to handle duplicate executables: keep-local, prefer-imported, merge-rates-max")
+
+	// Processes command flags
+	processesCmd.Flags().StringVar(&procFilter, "filter", "", "only show processes containing this text")
+
+	// Learn command flags
+	learnCmd.Flags().DurationVar(&learnFor, "threshold", 2*time.Minute, "how long an unknown app must stay in front before it's offered")
+	learnCmd.Flags().BoolVar(&learnAutoAdd, "auto-add", false, "add apps without asking")
+	learnCmd.Flags().BoolVar(&learnAnyWin, "any-window", false, "consider any foreground window, not just fullscreen ones")
+
+	scanInstalledCmd.Flags().Int64Var(&installedMB, "min-size", 1024, "skip programs whose reported size is smaller than this many MB")
+	scanInstalledCmd.Flags().BoolVar(&learnAutoAdd, "auto-add", false, "add every candidate without asking")
+	scanInstalledCmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list candidates")
+
+	// Set command flags
+	setCmd.Flags().DurationVar(&temporary, "temporary", 0, "revert to the previous rate after this duration (e.g. 5m)")
 
 	// Steam scan command flags
 	scanSteamCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be updated without saving")
-	scanSteamCmd.Flags().BoolVar(&force, "force", false, "force rescan even if recent scan exists")
+	scanEpicCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be updated without saving")
+	scanSteamCmd.Flags().BoolVar(&force, "force", false, "force rescan even if recent scan exists, re-reading every manifest")
+	scanSteamCmd.Flags().StringVar(&scanOutput, "output", "", "write scan results to this YAML/JSON file instead of updating the config")
+	scanSteamCmd.Flags().BoolVar(&mergeScan, "merge", false, "keep previously detected games from libraries missing in this scan")
+	scanSteamCmd.Flags().BoolVar(&pruneScan, "prune", false, "also remove detected games whose install folder no longer exists")
+	scanSteamCmd.Flags().BoolVar(&unmounted, "include-unmounted", false, "scan libraries Steam reports as not mounted")
+	scanSteamCmd.Flags().StringArrayVar(&excludes, "exclude", nil, "skip games whose name matches this glob or substring, repeatable")
+	scanSteamCmd.Flags().IntVar(&scanThreads, "threads", 0, "max games processed concurrently (default: CPU count, up to 4)")
+	scanSteamCmd.Flags().BoolVar(&scanOnce, "once", false, "always rescan, print a single result line and exit non-zero on failure (for scripts)")
+	scanSteamCmd.Flags().BoolVar(&incremental, "incremental", false, "save each library's games as soon as it is scanned (large libraries)")
+	scanSteamCmd.Flags().Int64Var(&minSizeMB, "min-size", 0, "skip games smaller than this many MB")
+	scanSteamCmd.Flags().BoolVar(&keepUnknown, "keep-unknown-size", false, "with --min-size, keep games whose size Steam didn't report")
+	scanSteamCmd.Flags().BoolVar(&scanUbisoft, "ubisoft", false, "also detect Ubisoft Connect games")
+	scanSteamCmd.Flags().DurationVar(&since, "since", 0, "only include games installed or updated within this window (e.g. 720h)")
 
 	// Add game command flags
 	addGameCmd.Flags().StringVar(&gameName, "name", "", "game name (required)")
@@ -88,312 +378,1505 @@ func init() {
 	addGameCmd.MarkFlagRequired("name")
 	addGameCmd.MarkFlagRequired("exe")
 
+	simulateCmd.Flags().StringVar(&gameExe, "exe", "", "configured game executable to pretend is running (required)")
+	simulateCmd.Flags().DurationVar(&simDuration, "duration", 30*time.Second, "how long the game stays \"running\"")
+	simulateCmd.MarkFlagRequired("exe")
+
 	// Remove game command flags
 	removeGameCmd.Flags().StringVar(&gameName, "name", "", "game name to remove (required)")
 	removeGameCmd.MarkFlagRequired("name")
 
+	// Capability probing flags
+	listCmd.Flags().BoolVar(&probeRates, "probe", false, "probe the connected mouse and mark unsupported rates (briefly changes the rate)")
+	infoCmd.Flags().BoolVar(&probeRates, "probe", false, "probe which polling rates the mouse accepts (briefly changes the rate)")
+
+	// Validate command flags
+	validateCmd.Flags().BoolVar(&watchConfig, "watch-config", false, "keep running and re-validate whenever the config file changes")
+
 	rootCmd.AddCommand(setCmd)
+	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(debugCmd)
 	rootCmd.AddCommand(scanSteamCmd)
+	rootCmd.AddCommand(scanEpicCmd)
 	rootCmd.AddCommand(addGameCmd)
 	rootCmd.AddCommand(removeGameCmd)
 	rootCmd.AddCommand(listGamesCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(metricsCmd)
+	rootCmd.AddCommand(setupCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(importConfigCmd)
+	rootCmd.AddCommand(exportGamesCmd)
+	rootCmd.AddCommand(simulateCmd)
+	rootCmd.AddCommand(enableGameCmd)
+	rootCmd.AddCommand(disableGameCmd)
+	rootCmd.AddCommand(importGamesCmd)
+	rootCmd.AddCommand(listLibrariesCmd)
+
+	rootCmd.AddCommand(learnCmd)
+	rootCmd.AddCommand(scanInstalledCmd)
+	rootCmd.AddCommand(diffScanCmd)
+	rootCmd.AddCommand(processesCmd)
+
+	profileCmd.AddCommand(profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+
+	serviceCmd.AddCommand(serviceInstallCmd)
+	serviceCmd.AddCommand(serviceUninstallCmd)
+	serviceCmd.AddCommand(serviceRunCmd)
+	rootCmd.AddCommand(serviceCmd)
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	rootCmd.AddCommand(configCmd)
 }
 
 func main() {
+	rootCmd.SilenceErrors = true
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		exitWithError(&CommandError{Code: codeUsageError, Err: err})
 	}
 }
 
-func initMouseController() (MouseControllerInterface, error) {
-	// Use Windows native HID API
-	controller, err := NewWindowsMouseController()
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Windows HID controller: %w", err)
+// loadConfig loads the config file, or builds a config from flags under --no-config
+func loadConfig() (*Config, error) {
+	if !noConfig {
+		return LoadConfig(configFile)
 	}
 
-	if verbose {
-		fmt.Println("✅ Using Windows native HID API")
+	config := DefaultConfig()
+	config.DefaultPollingRate = flagDefault
+	config.GamePollingRate = flagGameRate
+
+	if len(flagGames) > 0 {
+		config.CustomGames = make([]CustomGame, 0, len(flagGames))
+		for _, exe := range flagGames {
+			config.CustomGames = append(config.CustomGames, CustomGame{Name: exe, Executable: exe})
+		}
+	}
+
+	if problems := ValidateConfig(config); len(problems) > 0 {
+		return nil, problems[0]
+	}
+
+	return config, nil
+}
+
+// requireConfigFile rejects commands that only make sense with a config file
+func requireConfigFile(command string) error {
+	if noConfig {
+		return newCommandError(codeInvalidArgument, "%s modifies the config file and can't be used with --no-config", command)
 	}
-	return controller, nil
+	return nil
 }
 
-func runAutomator(cmd *cobra.Command, args []string) {
-	config, err := LoadConfig(configFile)
+func initMouseController(readOnly bool) (MouseControllerInterface, error) {
+	if err := setDeviceShareMode(shareMode); err != nil {
+		return nil, err
+	}
+
+	// Pick up device profile overrides and the rate cap; commands like set work without a valid config
+	profile := defaultDeviceProfile
+	if config, err := loadConfig(); err == nil {
+		setMaxPollingRate(config.MaxPollingRate)
+		if config.Device != nil {
+			profile = *config.Device
+			logDebugf("🔧 Using device profile %q (report ID 0x%02X)\n", config.Device.Name, config.Device.ReportID)
+		}
+	}
+	if slotFlag != "" {
+		if _, err := parseSlot(slotFlag); err != nil {
+			return nil, err
+		}
+		profile.Slot = slotFlag
+	}
+	setDeviceProfile(profile)
+
+	// --device pins one mouse; otherwise every connected mouse gets the rate,
+	// including ones plugged in later
+	if deviceFlag != "" {
+		controller, err := NewWindowsMouseController(deviceFlag, readOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Windows HID controller: %w", err)
+		}
+		logDebugf("✅ Using Windows native HID API\n")
+		return controller, nil
+	}
+
+	paths, err := LAMZUDevicePaths()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return nil, fmt.Errorf("failed to initialize Windows HID controller: failed to find LAMZU device: %w", err)
+	}
+	mice := &MultiMouseController{
+		discover: LAMZUDevicePaths,
+		open: func(path string) (MouseControllerInterface, error) {
+			return NewWindowsMouseController(path, readOnly)
+		},
+	}
+	var openErr error
+	for _, path := range paths {
+		controller, err := mice.open(path)
+		if err != nil {
+			logWarnf("⚠️ Skipping LAMZU device %s: %v\n", path, err)
+			openErr = err
+			continue
+		}
+		mice.mice = append(mice.mice, controller)
+	}
+	if len(mice.mice) == 0 {
+		return nil, fmt.Errorf("failed to initialize Windows HID controller: %w", openErr)
+	}
+
+	logDebugf("✅ Using Windows native HID API\n")
+	if len(mice.mice) > 1 {
+		logInfof("🖱️ Controlling %d LAMZU devices (use --device to pick one)\n", len(mice.mice))
+	}
+	return mice, nil
+}
+
+func runAutomator(cmd *cobra.Command, args []string) error {
+	// Guide first-time users before the default config gets written
+	if !daemon && isFirstRun() && isInteractive() {
+		wizard := NewSetupWizard()
+		fmt.Println("👋 No config found.")
+		if wizard.confirm("Run the setup wizard?", true) {
+			if err := wizard.Run(); err != nil {
+				logErrorf("❌ Setup failed: %v\n", err)
+			}
+			fmt.Println()
+		}
 	}
 
-	mouse, err := initMouseController()
+	config, err := loadConfig()
 	if err != nil {
-		log.Fatalf("Failed to initialize mouse controller: %v", err)
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
 	}
-	defer mouse.Close()
 
-	// Test mouse connection
-	if err := mouse.TestConnection(); err != nil {
-		log.Fatalf("Failed to connect to LAMZU mouse: %v", err)
+	// Catch bad rates from hand edits up front instead of failing on every switch
+	if problems := ValidateConfig(config); len(problems) > 0 {
+		for _, problem := range problems {
+			logErrorf("  - %v\n", problem)
+		}
+		return newCommandError(codeConfigError, "config has %d problem(s), fix them before starting (see lamzu-automator validate)", len(problems))
+	}
+	applyActiveProfile(config)
+
+	if observe {
+		return runObserve(config)
+	}
+
+	// Set initial polling rate once the device accepts commands
+	initialRate := scheduledRate(config, time.Now())
+	mouse, originalRate, err := connectWhenReady(config, initialRate)
+	if err != nil {
+		return err
 	}
+	defer mouse.Close()
 
-	fmt.Println("🎮 LAMZU Polling Rate Auto-Switch v1.0")
-	fmt.Println("✅ Mouse connected successfully")
+	logInfof("🎮 LAMZU Polling Rate Auto-Switch v1.0\n")
+	logInfof("✅ Mouse connected successfully\n")
 
 	// Initialize notification manager
-	notificationManager := NewNotificationManager()
+	notificationManager := NewNotificationManager(config.NotifyCooldown)
 
-	// Set initial polling rate
-	if err := mouse.SetPollingRate(config.DefaultPollingRate); err != nil {
-		log.Fatalf("Failed to set initial polling rate: %v", err)
+	logInfof("📊 Default polling rate: %dHz\n", config.DefaultPollingRate)
+	if initialRate != config.DefaultPollingRate {
+		logInfof("🕐 Scheduled polling rate now: %dHz\n", initialRate)
 	}
-
-	fmt.Printf("📊 Default polling rate: %dHz\n", config.DefaultPollingRate)
-	fmt.Printf("🎯 Game polling rate: %dHz\n", config.GamePollingRate)
+	logInfof("🎯 Game polling rate: %dHz\n", config.GamePollingRate)
 	
-	totalGames := len(config.Games) + len(config.DetectedGames) + len(config.CustomGames)
-	fmt.Printf("🔍 Monitoring %d games\n", totalGames)
+	totalGames := len(configuredGames(config))
+	logInfof("🔍 Monitoring %d games\n", totalGames)
 
 	// Show app started notification
 	notificationManager.ShowAppStarted()
 
-	watcher := NewGameWatcher(config, mouse, notificationManager)
+	watcher := NewGameWatcher(config, mouse)
+	go notifyEvents(watcher.Events(), notificationManager)
+	metrics := serveMetrics(watcher)
+
+	if config.PauseHotkey != "" {
+		hotkey, err := startHotkey(config.PauseHotkey, func() {
+			paused := watcher.TogglePause()
+			if paused {
+				logInfof("⏸️ Monitoring paused\n")
+			} else {
+				logInfof("▶️ Monitoring resumed\n")
+			}
+			notificationManager.ShowMonitoringState(paused)
+		})
+		if err != nil {
+			logWarnf("⚠️ %v\n", err)
+		} else {
+			logInfof("⌨️ Press %s to pause/resume monitoring\n", config.PauseHotkey)
+			defer hotkey.Stop()
+		}
+	}
 
 	if daemon {
-		fmt.Println("🚀 Starting in daemon mode...")
+		logInfof("🚀 Starting in daemon mode...\n")
 		runDaemon(watcher)
 	} else {
-		fmt.Println("🎮 Starting in interactive mode (Ctrl+C to stop)...")
+		logInfof("🎮 Starting in interactive mode (Ctrl+C to stop)...\n")
 		runInteractive(watcher)
 	}
+
+	metrics.Stop()
+	shutdown(watcher, mouse, config, originalRate)
+	return nil
 }
 
-func runSetRate(cmd *cobra.Command, args []string) {
-	rate := parsePollingRate(args[0])
-	if rate == 0 {
-		fmt.Fprintf(os.Stderr, "Invalid polling rate: %s\n", args[0])
-		fmt.Fprintf(os.Stderr, "Valid rates: 500, 1000, 2000, 4000, 8000\n")
-		os.Exit(1)
+// runObserve watches the device rate read-only, logging every change made by
+// other software without writing anything or switching for games
+func runObserve(config *Config) error {
+	mouse, err := initMouseController(true)
+	if err != nil {
+		return newCommandError(codeDeviceError, "failed to initialize mouse controller: %w", err)
 	}
+	defer mouse.Close()
 
-	mouse, err := initMouseController()
+	watcher := NewGameWatcher(config, mouse)
+	watcher.observeOnly = true
+	watcher.recordSightings = false
+	rate, err := mouse.GetPollingRate()
 	if err != nil {
-		log.Fatalf("Failed to initialize mouse controller: %v", err)
+		return newCommandError(codeDeviceError, "failed to read polling rate: %w", err)
 	}
-	defer mouse.Close()
+	watcher.appliedRate = rate
+	logInfof("👀 Observe mode: polling rate is %dHz, checking every %s without writing (Ctrl+C to stop)\n", rate, config.CheckInterval)
 
-	if err := mouse.SetPollingRate(rate); err != nil {
-		log.Fatalf("Failed to set polling rate: %v", err)
+	runInteractive(watcher)
+	watcher.Stop()
+
+	m := watcher.Metrics()
+	logDebugf("📊 Session: %d checks, %d errors over %s\n", m.Checks, m.Errors, m.Uptime)
+	return nil
+}
+
+// Startup readiness defaults, used when the config leaves them unset
+const (
+	defaultStartupDelay   = time.Second
+	defaultStartupTimeout = 30 * time.Second
+	startupRetryInterval  = time.Second
+)
+
+// connectWhenReady waits startup_delay, then opens the mouse and applies the
+// initial rate, retrying until startup_timeout. At boot the HID device can show
+// up a little after the app starts and reject the first commands. It also
+// returns the rate the device had before, or 0 if it couldn't be read.
+func connectWhenReady(config *Config, rate int) (MouseControllerInterface, int, error) {
+	delay := config.StartupDelay
+	if delay <= 0 {
+		delay = defaultStartupDelay
 	}
+	timeout := config.StartupTimeout
+	if timeout <= 0 {
+		timeout = defaultStartupTimeout
+	}
+
+	logDebugf("⏳ Waiting %s for the device to be ready...\n", delay)
+	time.Sleep(delay)
+
+	deadline := time.Now().Add(timeout)
+	for attempt := 1; ; attempt++ {
+		mouse, original, err := openAndApply(rate)
+		if err == nil {
+			if attempt > 1 {
+				logInfof("🔁 Initial polling rate applied after %d attempts\n", attempt)
+			}
+			return mouse, original, nil
+		}
 
-	fmt.Printf("✅ Polling rate set to %dHz\n", rate)
+		if time.Now().After(deadline) {
+			return nil, 0, err
+		}
+		logDebugf("⚠️ Device not ready (attempt %d): %v\n", attempt, err)
+		time.Sleep(startupRetryInterval)
+	}
 }
 
-func runListRates(cmd *cobra.Command, args []string) {
-	fmt.Println("Available polling rates:")
-	for rate := range pollingRateMap {
-		fmt.Printf("  %dHz\n", rate)
+// openAndApply opens the mouse and applies rate, returning the rate the device
+// had before, or 0 if it couldn't be read
+func openAndApply(rate int) (MouseControllerInterface, int, error) {
+	mouse, err := initMouseController(false)
+	if err != nil {
+		return nil, 0, newCommandError(codeDeviceError, "failed to initialize mouse controller: %w", err)
+	}
+	if err := mouse.TestConnection(); err != nil {
+		mouse.Close()
+		return nil, 0, newCommandError(codeDeviceError, "failed to connect to LAMZU mouse: %w", err)
+	}
+
+	original, err := mouse.GetPollingRate()
+	if err != nil {
+		logDebugf("⚠️ Could not read the polling rate before startup, exit will restore default_polling_rate: %v\n", err)
+		original = 0
+	}
+	if err := mouse.SetPollingRate(rate); err != nil {
+		mouse.Close()
+		return nil, 0, newCommandError(codeDeviceError, "failed to set initial polling rate: %w", err)
 	}
+	return mouse, original, nil
 }
 
-func runDebug(cmd *cobra.Command, args []string) {
-	fmt.Println("🔧 LAMZU Device Debug Mode")
-	fmt.Println("==========================")
+// loadRateBytes reads the config so device.rate_bytes is applied before rates
+// are parsed or listed; these commands also work without a valid config
+func loadRateBytes() {
+	loadConfig()
+}
 
-	// Try to connect
-	fmt.Println("🔌 Testing connection...")
-	mouse, err := initMouseController()
+func runSetRate(cmd *cobra.Command, args []string) error {
+	loadRateBytes()
+	rate := parsePollingRate(args[0])
+	if rate == 0 {
+		return newCommandError(codeInvalidArgument, "invalid polling rate: %s (valid rates: %s)", args[0], pollingRateList())
+	}
+
+	mouse, err := initMouseController(false)
 	if err != nil {
-		fmt.Printf("❌ Failed to connect: %v\n", err)
-		return
+		return newCommandError(codeDeviceError, "failed to initialize mouse controller: %w", err)
 	}
 	defer mouse.Close()
 
-	// Test connection
-	if err := mouse.TestConnection(); err != nil {
-		fmt.Printf("❌ Connection test failed: %v\n", err)
-		return
+	var previous int
+	if temporary > 0 {
+		previous, err = mouse.GetPollingRate()
+		if err != nil {
+			return newCommandError(codeDeviceError, "failed to read current polling rate: %w", err)
+		}
 	}
 
-	fmt.Println("✅ Connection successful!")
-
-	// Test setting polling rates
-	fmt.Println("\n🎯 Testing polling rate changes...")
-	testRates := []int{1000, 2000, 1000}
+	if err := mouse.SetPollingRate(rate); err != nil {
+		return newCommandError(codeDeviceError, "failed to set polling rate: %w", err)
+	}
 
-	for _, rate := range testRates {
-		fmt.Printf("Setting %dHz... ", rate)
-		if err := mouse.SetPollingRate(rate); err != nil {
-			fmt.Printf("❌ Failed: %v\n", err)
-		} else {
-			fmt.Printf("✅ Success!\n")
-		}
+	fmt.Printf("✅ Polling rate set to %dHz\n", capPollingRate(rate))
+	if temporary <= 0 {
+		return nil
 	}
 
-	fmt.Println("\n🎉 All tests completed!")
+	return revertAfter(mouse, previous, temporary)
 }
 
-func runInteractive(watcher *GameWatcher) {
-	watcher.Start()
-	defer watcher.Stop()
+// revertAfter waits for the duration or Ctrl+C, then restores the previous rate
+func revertAfter(mouse MouseControllerInterface, previous int, duration time.Duration) error {
+	fmt.Printf("⏳ Reverting to %dHz in %s (Ctrl+C to revert now)\n", previous, duration)
 
-	// Wait for interrupt signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
+	defer signal.Stop(c)
 
-	fmt.Println("\n👋 Shutting down...")
-}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
 
-func runDaemon(watcher *GameWatcher) {
-	// In a real implementation, you'd use Windows service APIs
-	// For now, this runs as a background process
-	watcher.Start()
-	defer watcher.Stop()
+	select {
+	case <-timer.C:
+	case <-c:
+		fmt.Println("\n🛑 Interrupted, reverting early")
+	}
 
-	// Keep running until system signal
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
-}
+	if err := mouse.SetPollingRate(previous); err != nil {
+		return newCommandError(codeDeviceError, "failed to restore polling rate: %w", err)
+	}
 
-// Steam scanning command implementations
-func runScanSteam(cmd *cobra.Command, args []string) {
-	fmt.Println("🔍 Scanning for Steam games...")
+	fmt.Printf("↩️ Polling rate restored to %dHz\n", previous)
+	return nil
+}
 
-	// Initialize Steam detector
-	config, err := LoadConfig(configFile)
+func runGetRate(cmd *cobra.Command, args []string) error {
+	mouse, err := initMouseController(true)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return newCommandError(codeDeviceError, "failed to initialize mouse controller: %w", err)
 	}
+	defer mouse.Close()
 
-	detector := NewSteamDetector(config)
-
-	// Find Steam installation
-	steamPath, err := detector.FindSteamInstallation()
+	rate, err := mouse.GetPollingRate()
 	if err != nil {
-		fmt.Printf("❌ Steam installation not found: %v\n", err)
-		fmt.Println("💡 Make sure Steam is installed or use --config to specify a custom config file")
-		os.Exit(1)
+		return newCommandError(codeDeviceError, "failed to read polling rate: %w", err)
 	}
 
-	if verbose {
-		fmt.Printf("✅ Steam found at: %s\n", steamPath)
+	if jsonOutput {
+		fmt.Printf("{\"polling_rate\": %d}\n", rate)
+		return nil
 	}
 
-	// Discover libraries
-	libraries, err := detector.DiscoverLibraries(steamPath)
-	if err != nil {
-		log.Fatalf("Failed to discover Steam libraries: %v", err)
+	fmt.Printf("🖱️ Current polling rate: %dHz\n", rate)
+	return nil
+}
+
+func runListRates(cmd *cobra.Command, args []string) error {
+	loadRateBytes()
+	var supported map[int]bool
+	if probeRates {
+		mouse, err := initMouseController(false)
+		if err != nil {
+			return newCommandError(codeDeviceError, "failed to initialize mouse controller: %w", err)
+		}
+		defer mouse.Close()
+
+		rates, err := mouse.SupportedRates()
+		if err != nil {
+			return newCommandError(codeDeviceError, "failed to probe supported rates: %w", err)
+		}
+		supported = make(map[int]bool)
+		for _, rate := range rates {
+			supported[rate] = true
+		}
 	}
 
-	// Check if we should skip scan due to recent scan
-	if !force && config.Steam != nil {
-		timeSinceLastScan := time.Since(config.Steam.LastScan)
-		if timeSinceLastScan < 24*time.Hour {
-			fmt.Printf("⏰ Recent scan found (%.1f hours ago)\n", timeSinceLastScan.Hours())
-			fmt.Println("Use --force to rescan anyway")
-			return
+	fmt.Println("Available polling rates:")
+	for _, rate := range sortedPollingRates() {
+		if supported != nil && !supported[rate] {
+			fmt.Printf("  %dHz (not supported by this mouse)\n", rate)
+		} else {
+			fmt.Printf("  %dHz\n", rate)
 		}
 	}
+	return nil
+}
 
-	// Scan for games
-	scanner := NewGameScanner(libraries)
-	games, err := scanner.ScanAllLibraries()
-	if err != nil && verbose {
-		fmt.Printf("⚠️ Scan completed with warnings: %v\n", err)
+func runInfo(cmd *cobra.Command, args []string) error {
+	mouse, err := initMouseController(!probeRates)
+	if err != nil {
+		return newCommandError(codeDeviceError, "failed to initialize mouse controller: %w", err)
 	}
+	defer mouse.Close()
 
-	fmt.Printf("🎮 Found %d games across %d libraries\n", len(games), len(libraries))
+	info := mouse.DeviceInfo()
+	fmt.Println("🖱️ LAMZU Device Info")
+	fmt.Printf("  Path:    %s\n", info.Path)
+	fmt.Printf("  VID/PID: 0x%04X / 0x%04X\n", info.VendorID, info.ProductID)
+	fmt.Printf("  Version: 0x%04X\n", info.Version)
 
-	if dryRun {
-		fmt.Println("\n📋 Dry run - no changes saved:")
-		fmt.Println("Steam libraries:")
-		for _, lib := range libraries {
-			fmt.Printf("  - %s: %s\n", lib.Label, lib.Path)
-		}
-		fmt.Println("\nDetected games:")
-		for _, game := range games {
-			sizeMB := game.SizeMB
-			if sizeMB == 0 {
-				fmt.Printf("  - %s (%s)\n", game.Name, game.Executable)
-			} else {
-				fmt.Printf("  - %s (%s, %.1f GB)\n", game.Name, game.Executable, float64(sizeMB)/1024)
-			}
-		}
-		return
+	if rate, err := mouse.GetPollingRate(); err != nil {
+		fmt.Printf("  Polling rate: unknown (%v)\n", err)
+	} else {
+		fmt.Printf("  Polling rate: %dHz\n", rate)
 	}
 
-	// Update config
-	updater := NewConfigUpdater(configFile)
-	if err := updater.UpdateWithSteamData(steamPath, libraries, games); err != nil {
-		log.Fatalf("Failed to update config: %v", err)
+	fmt.Println("  Rate bytes:")
+	for _, rate := range sortedPollingRates() {
+		marker := ""
+		if value, ok := defaultPollingRateMap[rate]; !ok || value != pollingRateMap[rate] {
+			marker = " (device.rate_bytes)"
+		}
+		fmt.Printf("    %5dHz -> 0x%02X%s\n", rate, pollingRateMap[rate], marker)
 	}
 
-	fmt.Printf("✅ Config updated with %d games\n", len(games))
-	
-	// Display summary
-	detected, custom, legacy, err := updater.GetGameCounts()
-	if err == nil {
-		fmt.Printf("📊 Games: %d detected, %d custom", detected, custom)
-		if legacy > 0 {
-			fmt.Printf(", %d legacy", legacy)
+	if probeRates {
+		rates, err := mouse.SupportedRates()
+		if err != nil {
+			return newCommandError(codeDeviceError, "failed to probe supported rates: %w", err)
 		}
-		fmt.Println()
+		fmt.Printf("  Supported rates: %v\n", rates)
 	}
+
+	return nil
 }
 
-func runAddGame(cmd *cobra.Command, args []string) {
-	updater := NewConfigUpdater(configFile)
-	
-	if err := updater.AddCustomGame(gameName, gameExe, gamePath); err != nil {
-		fmt.Printf("❌ Failed to add game: %v\n", err)
-		os.Exit(1)
+func runSetup(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("setup"); err != nil {
+		return err
+	}
+	if !isInteractive() {
+		fmt.Println("ℹ️ Setup needs an interactive console, skipping")
+		return nil
+	}
+
+	if err := NewSetupWizard().Run(); err != nil {
+		return newCommandError(codeConfigError, "%w", err)
+	}
+	return nil
+}
+
+// runMetrics asks the running watcher (foreground or service) for its metrics
+func runMetrics(cmd *cobra.Command, args []string) error {
+	m, err := readMetrics()
+	if err != nil {
+		return newCommandError(codeUnknown, "%w", err)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return newCommandError(codeUnknown, "failed to encode metrics: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("📊 Watcher running for %s\n", m.Uptime)
+	fmt.Printf("🖱️ Current polling rate: %dHz\n", m.CurrentRate)
+	if m.CurrentGame != "" {
+		fmt.Printf("🎮 Current game: %s\n", m.CurrentGame)
+	}
+	fmt.Printf("   Checks: %d, switches: %d, reconciles: %d, reconnects: %d, errors: %d\n", m.Checks, m.Switches, m.Reconciles, m.Reconnects, m.Errors)
+	return nil
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := buildInfo()
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return newCommandError(codeUnknown, "failed to encode version: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("lamzu-automator %s\n", info.Version)
+	fmt.Printf("  Commit: %s\n", info.Commit)
+	fmt.Printf("  Go:     %s\n", info.GoVersion)
+	fmt.Println("  Supported devices:")
+	for _, device := range info.Devices {
+		fmt.Printf("    %s (VID 0x%04X, PID 0x%04X, interface %d)\n", device.Name, device.VendorID, device.ProductID, device.Interface)
+	}
+	return nil
+}
+
+func runDebug(cmd *cobra.Command, args []string) error {
+	fmt.Println("🔧 LAMZU Device Debug Mode")
+	fmt.Println("==========================")
+
+	// Try to connect
+	fmt.Println("🔌 Testing connection...")
+	mouse, err := initMouseController(false)
+	if err != nil {
+		return newCommandError(codeDeviceError, "failed to connect: %w", err)
+	}
+	defer mouse.Close()
+
+	// Test connection
+	if err := mouse.TestConnection(); err != nil {
+		return newCommandError(codeDeviceError, "connection test failed: %w", err)
+	}
+
+	fmt.Println("✅ Connection successful!")
+
+	info := mouse.DeviceInfo()
+	fmt.Printf("\n📋 Interface: %s\n", info.Path)
+	if info.Caps != nil {
+		fmt.Printf("  Usage page/usage: 0x%04X / 0x%04X\n", info.Caps.UsagePage, info.Caps.Usage)
+		fmt.Printf("  Report lengths:   input %d, output %d, feature %d bytes\n", info.Caps.InputLength, info.Caps.OutputLength, info.Caps.FeatureLength)
+	} else {
+		fmt.Println("  Capabilities: unavailable")
+	}
+
+	// Test setting polling rates
+	fmt.Println("\n🎯 Testing polling rate changes...")
+	testRates := []int{1000, 2000, 1000}
+
+	for _, rate := range testRates {
+		fmt.Printf("Setting %dHz... ", rate)
+		if err := mouse.SetPollingRate(rate); err != nil {
+			fmt.Printf("❌ Failed: %v\n", err)
+		} else {
+			fmt.Printf("✅ Success!\n")
+		}
+	}
+
+	fmt.Println("\n🎉 All tests completed!")
+	return nil
+}
+
+// runSimulate runs the real watcher and device writes with a fake process added
+// to detection for --duration, then waits for the switch back
+func runSimulate(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+	if problems := ValidateConfig(config); len(problems) > 0 {
+		return newCommandError(codeConfigError, "config has %d problem(s), fix them first (see lamzu-automator validate)", len(problems))
+	}
+	applyActiveProfile(config)
+
+	executable := normalizeProcessName(gameExe)
+	var game *watchedGame
+	for _, candidate := range configuredGames(config) {
+		if matchKey(config, candidate.Executable) == matchKey(config, executable) {
+			game = &candidate
+			break
+		}
+	}
+	if game == nil {
+		return newCommandError(codeInvalidArgument, "%s is not a configured game (see lamzu-automator list-games)", executable)
+	}
+	if game.Parent != "" {
+		logWarnf("⚠️ %s only matches when started by %s, so the simulated process won't be detected\n", game.Name, game.Parent)
+	}
+
+	mouse, err := initMouseController(false)
+	if err != nil {
+		return newCommandError(codeDeviceError, "failed to initialize mouse controller: %w", err)
+	}
+	defer mouse.Close()
+
+	watcher := NewGameWatcher(config, mouse)
+	watcher.recordSightings = false
+	remove := watcher.InjectProcess(executable)
+
+	logInfof("🧪 Simulating %s (%s) for %s, Ctrl+C to stop early\n", game.Name, executable, simDuration)
+	watcher.Start()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	select {
+	case <-time.After(simDuration):
+	case <-interrupt:
+	}
+
+	remove()
+	logInfof("🧪 %s \"exited\", waiting for the switch back...\n", executable)
+
+	// The next check sees the game gone; allow a couple of intervals for it
+	timeout := time.After(2*config.CheckInterval + time.Second)
+	for waiting := true; waiting; {
+		select {
+		case event := <-watcher.Events():
+			waiting = event.Type != EventGameClosed && event.Type != EventError
+		case <-timeout:
+			logWarnf("⚠️ No switch back observed, check the log above\n")
+			waiting = false
+		case <-interrupt:
+			waiting = false
+		}
+	}
+
+	watcher.Stop()
+	fmt.Println("✅ Simulation finished")
+	return nil
+}
+
+func runInteractive(watcher *GameWatcher) {
+	watcher.Start()
+
+	// Wait for interrupt signal
+	waitForShutdownSignal()
+
+	logInfof("👋 Shutting down...\n")
+}
+
+func runDaemon(watcher *GameWatcher) {
+	// Runs as a background process; `service install` registers a real Windows service
+	watcher.Start()
+
+	// Keep running until system signal
+	waitForShutdownSignal()
+}
+
+func runServiceInstall(cmd *cobra.Command, args []string) error {
+	if err := installService(); err != nil {
+		return newCommandError(codeServiceError, "%w", err)
+	}
+	fmt.Printf("✅ Service %s installed, it starts with Windows using %s\n", serviceName, configFile)
+	fmt.Printf("💡 Start it now with: sc start %s (logs go to the Windows event log)\n", serviceName)
+	return nil
+}
+
+func runServiceUninstall(cmd *cobra.Command, args []string) error {
+	if err := uninstallService(); err != nil {
+		return newCommandError(codeServiceError, "%w", err)
+	}
+	fmt.Printf("✅ Service %s removed\n", serviceName)
+	return nil
+}
+
+func runServiceRun(cmd *cobra.Command, args []string) error {
+	if err := runService(); err != nil {
+		return newCommandError(codeServiceError, "%w", err)
+	}
+	return nil
+}
+
+func waitForShutdownSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+	signal.Stop(c)
+}
+
+// shutdownTimeout bounds how long cleanup may take before the process exits anyway
+const shutdownTimeout = 5 * time.Second
+
+// shutdown stops the watcher and restores the rate the device had at startup
+// (originalRate, or default_polling_rate when that couldn't be read), giving up
+// after shutdownTimeout. The log file is closed last.
+func shutdown(watcher *GameWatcher, mouse MouseControllerInterface, config *Config, originalRate int) {
+	defer closeLogging()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		watcher.Stop()
+
+		if verbose {
+			m := watcher.Metrics()
+			logDebugf("📊 Session: %d checks, %d switches, %d reconciles, %d reconnects, %d errors over %s\n", m.Checks, m.Switches, m.Reconciles, m.Reconnects, m.Errors, m.Uptime)
+		}
+
+		if profile, ok := config.Profiles[config.ShutdownProfile]; ok && config.ShutdownProfile != "" {
+			if err := mouse.SetPollingRate(profile.DefaultPollingRate); err != nil {
+				logWarnf("⚠️ Failed to apply shutdown profile %s: %v\n", config.ShutdownProfile, err)
+			} else {
+				logDebugf("🔋 Applied shutdown profile %s: %dHz\n", config.ShutdownProfile, profile.DefaultPollingRate)
+			}
+		} else if config.RestoreOnExit {
+			rate := originalRate
+			if rate == 0 {
+				rate = config.DefaultPollingRate
+			}
+			if err := mouse.SetPollingRate(rate); err != nil {
+				logWarnf("⚠️ Failed to restore the polling rate: %v\n", err)
+			} else {
+				logDebugf("🏠 Restored polling rate: %dHz\n", rate)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		logWarnf("⚠️ Shutdown did not finish within %s, exiting anyway\n", shutdownTimeout)
+	}
+}
+
+// Steam scanning command implementations
+func runScanSteam(cmd *cobra.Command, args []string) error {
+	scanLogln("🔍 Scanning for Steam games...")
+
+	// Initialize Steam detector
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	detector := NewSteamDetector(config)
+
+	// Find Steam installation
+	steamPath, err := detector.FindSteamInstallation()
+	if err != nil {
+		if !jsonOutput {
+			scanLogln("💡 Make sure Steam is installed or use --config to specify a custom config file")
+		}
+		return newCommandError(codeSteamNotFound, "steam installation not found: %w", err)
+	}
+
+	if verbose {
+		scanLogf("✅ Steam found at: %s\n", steamPath)
+	}
+
+	// Discover libraries
+	libraries, err := detector.DiscoverLibraries(steamPath)
+	if err != nil {
+		return newCommandError(codeScanError, "failed to discover Steam libraries: %w", err)
+	}
+
+	// Check if we should skip scan due to recent scan
+	if !force && !scanOnce && scanOutput == "" && config.Steam != nil {
+		timeSinceLastScan := time.Since(config.Steam.LastScan)
+		if timeSinceLastScan < 24*time.Hour {
+			scanLogf("⏰ Recent scan found (%.1f hours ago)\n", timeSinceLastScan.Hours())
+			scanLogln("Use --force to rescan anyway")
+			return nil
+		}
+	}
+
+	// Scan for games
+	scanner := NewGameScanner(libraries, scanThreads)
+	scanner.SetSearchSubdirs(config.ExeSearchSubdirs)
+	if config.Steam != nil {
+		scanner.SetNameOverrides(config.Steam.NameOverrides)
+	}
+	if !dryRun && !noConfig {
+		scanner.UseManifestCache(manifestCachePath(), force)
+	}
+	if incremental && !dryRun && !noConfig && scanOutput == "" {
+		return runIncrementalScan(scanner, config, steamPath, libraries)
+	}
+
+	games, err := scanner.ScanAllLibraries()
+	if err != nil && verbose {
+		scanLogf("⚠️ Scan completed with warnings: %v\n", err)
+	}
+
+	if scanUbisoft {
+		games = append(games, scanUbisoftGames(config)...)
+	}
+
+	if since > 0 {
+		total := len(games)
+		games = FilterRecentGames(games, since)
+		scanLogf("🕐 %d of %d games updated within the last %s\n", len(games), total, since)
+	}
+
+	if len(excludes) > 0 {
+		total := len(games)
+		games, err = ExcludeGames(games, excludes)
+		if err != nil {
+			return newCommandError(codeInvalidArgument, "%w", err)
+		}
+		scanLogf("🚫 Excluded %d games matching --exclude\n", total-len(games))
+	}
+
+	if minSizeMB > 0 {
+		total := len(games)
+		games = FilterGamesBySize(games, minSizeMB, keepUnknown)
+		scanLogf("📏 Excluded %d games smaller than %d MB\n", total-len(games), minSizeMB)
+	}
+
+	scanLogf("🎮 Found %d games across %d libraries\n", len(games), len(libraries))
+
+	if scanOutput != "" {
+		result := ScanResult{
+			SteamPath: steamPath,
+			ScannedAt: time.Now(),
+			Libraries: libraries,
+			Games:     games,
+		}
+		if err := WriteScanResult(scanOutput, result); err != nil {
+			return newCommandError(codeScanError, "failed to write scan results: %w", err)
+		}
+		scanLogf("💾 Scan results written to %s (config not modified)\n", scanOutput)
+		scanResultLine("wrote %d games across %d libraries to %s", len(games), len(libraries), scanOutput)
+		return nil
+	}
+
+	if dryRun || noConfig {
+		scanLogln("\n📋 Dry run - no changes saved:")
+		scanLogln("Steam libraries:")
+		for _, lib := range libraries {
+			scanLogf("  - %s: %s\n", lib.Label, lib.Path)
+		}
+		scanLogln("\nDetected games:")
+		for _, game := range games {
+			sizeMB := game.SizeMB
+			if sizeMB == 0 {
+				scanLogf("  - %s (%s)\n", game.Name, game.Executable)
+			} else {
+				scanLogf("  - %s (%s, %.1f GB)\n", game.Name, game.Executable, float64(sizeMB)/1024)
+			}
+		}
+		scanResultLine("found %d games across %d libraries (dry run, not saved)", len(games), len(libraries))
+		return nil
+	}
+
+	// Update config
+	updater := NewConfigUpdater(configFile)
+	additive := mergeScan || (config.Steam != nil && config.Steam.MergeScans)
+	steamGames, ubisoftGames := splitUbisoftGames(games)
+	changes, err := updater.UpdateWithSteamData(steamPath, libraries, steamGames, additive, pruneScan)
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to update config: %w", err)
+	}
+	if scanUbisoft {
+		added, err := updater.UpdateWithUbisoftGames(ubisoftGames)
+		if err != nil {
+			return newCommandError(codeConfigError, "failed to update config: %w", err)
+		}
+		changes.Added += added
+	}
+
+	scanLogf("✅ Config updated with %d games (%d new)\n", len(games), changes.Added)
+	logPrunedGames(changes.Pruned)
+	
+	// Display summary
+	detected, custom, legacy, err := updater.GetGameCounts()
+	if err == nil {
+		scanLogf("📊 Games: %d detected, %d custom", detected, custom)
+		if legacy > 0 {
+			scanLogf(", %d legacy", legacy)
+		}
+		scanLogln()
+	}
+	scanResultLine("saved %d games across %d libraries to %s", len(games), len(libraries), configFile)
+	return nil
+}
+
+func runDiffScan(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	detector := NewSteamDetector(config)
+	steamPath, err := detector.FindSteamInstallation()
+	if err != nil {
+		return newCommandError(codeSteamNotFound, "steam installation not found: %w", err)
+	}
+
+	libraries, err := detector.DiscoverLibraries(steamPath)
+	if err != nil {
+		return newCommandError(codeScanError, "failed to discover Steam libraries: %w", err)
+	}
+
+	scanner := NewGameScanner(libraries, 0)
+	scanner.SetSearchSubdirs(config.ExeSearchSubdirs)
+	if config.Steam != nil {
+		scanner.SetNameOverrides(config.Steam.NameOverrides)
+	}
+	games, err := scanner.ScanAllLibraries()
+	if err != nil && verbose {
+		fmt.Printf("⚠️ Scan completed with warnings: %v\n", err)
+	}
+
+	added, removed, changed := DiffDetectedGames(config.DetectedGames, games)
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"added":   added,
+			"removed": removed,
+			"changed": changed,
+		}, "", "  ")
+		if err != nil {
+			return newCommandError(codeUnknown, "failed to encode diff: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, game := range added {
+		fmt.Printf("  + %s (%s)\n", game.Name, game.Executable)
+	}
+	for _, game := range removed {
+		fmt.Printf("  - %s (%s)\n", game.Name, game.Executable)
+	}
+	for _, change := range changed {
+		fmt.Printf("  ~ %s\n", change.After.Name)
+		if change.Before.Name != change.After.Name {
+			fmt.Printf("      name: %s -> %s\n", change.Before.Name, change.After.Name)
+		}
+		if !strings.EqualFold(change.Before.Executable, change.After.Executable) {
+			fmt.Printf("      executable: %s -> %s\n", change.Before.Executable, change.After.Executable)
+		}
+		if !strings.EqualFold(change.Before.InstallPath, change.After.InstallPath) {
+			fmt.Printf("      path: %s -> %s\n", change.Before.InstallPath, change.After.InstallPath)
+		}
+	}
+
+	fmt.Printf("📊 %d added, %d removed, %d changed, %d unchanged\n",
+		len(added), len(removed), len(changed), len(games)-len(added)-len(changed))
+	return nil
+}
+
+// scanUbisoftGames detects Ubisoft Connect games for --ubisoft; a missing
+// launcher is reported but doesn't fail the Steam scan
+func scanUbisoftGames(config *Config) []Game {
+	detector := NewUbisoftDetector()
+	detector.scanner.SetSearchSubdirs(config.ExeSearchSubdirs)
+	games, err := detector.FindGames()
+	if err != nil {
+		scanLogf("⚠️ Ubisoft Connect: %v\n", err)
+		return nil
+	}
+	scanLogf("🎮 Found %d Ubisoft Connect games\n", len(games))
+	return games
+}
+
+func runScanEpic(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	scanLogln("🔍 Scanning for Epic Games Launcher games...")
+	detector := NewEpicDetector()
+	detector.scanner.SetSearchSubdirs(config.ExeSearchSubdirs)
+	games, err := detector.FindGames()
+	if err != nil {
+		return newCommandError(codeScanError, "%w", err)
+	}
+	scanLogf("🎮 Found %d Epic games\n", len(games))
+
+	if dryRun || noConfig {
+		scanLogln("\n📋 Dry run - no changes saved:")
+		for _, game := range games {
+			scanLogf("  - %s (%s)\n", game.Name, game.Executable)
+		}
+		return nil
+	}
+
+	added, err := NewConfigUpdater(configFile).UpdateWithEpicGames(games)
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to update config: %w", err)
+	}
+	scanLogf("✅ Config updated: %d Epic games (%d new)\n", len(games), added)
+	return nil
+}
+
+// logPrunedGames reports games removed by scan-steam --prune
+func logPrunedGames(pruned []Game) {
+	if !pruneScan {
+		return
+	}
+	scanLogf("🗑️ Pruned %d games whose install folder is gone\n", len(pruned))
+	for _, game := range pruned {
+		scanLogf("  - %s (%s)\n", game.Name, game.InstallPath)
+	}
+}
+
+// runIncrementalScan saves games library by library as the scanner finishes them
+func runIncrementalScan(scanner *GameScanner, config *Config, steamPath string, libraries []Library) error {
+	updater := NewConfigUpdater(configFile)
+	additive := mergeScan || (config.Steam != nil && config.Steam.MergeScans)
+	pruned, err := updater.BeginIncrementalScan(steamPath, libraries, additive, pruneScan)
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to update config: %w", err)
+	}
+	logPrunedGames(pruned)
+
+	var saved int
+	var saveErr error
+	scanner.StreamLibraries(func(library Library, games []Game) {
+		if since > 0 {
+			games = FilterRecentGames(games, since)
+		}
+		if len(excludes) > 0 {
+			var err error
+			if games, err = ExcludeGames(games, excludes); err != nil {
+				saveErr = newCommandError(codeInvalidArgument, "%w", err)
+				return
+			}
+		}
+		if minSizeMB > 0 {
+			games = FilterGamesBySize(games, minSizeMB, keepUnknown)
+		}
+
+		if err := updater.SaveLibraryGames(library, games); err != nil {
+			scanLogf("❌ Failed to save games from %s: %v\n", library.Label, err)
+			saveErr = newCommandError(codeConfigError, "failed to update config: %w", err)
+			return
+		}
+		saved += len(games)
+		scanLogf("💾 Saved %d games from %s\n", len(games), library.Label)
+	})
+
+	if _, err := scanner.ScanAllLibraries(); err != nil && verbose {
+		scanLogf("⚠️ Scan completed with warnings: %v\n", err)
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+
+	if scanUbisoft {
+		games := scanUbisoftGames(config)
+		if len(excludes) > 0 {
+			var err error
+			if games, err = ExcludeGames(games, excludes); err != nil {
+				return newCommandError(codeInvalidArgument, "%w", err)
+			}
+		}
+		if minSizeMB > 0 {
+			games = FilterGamesBySize(games, minSizeMB, keepUnknown)
+		}
+		if _, err := updater.UpdateWithUbisoftGames(games); err != nil {
+			return newCommandError(codeConfigError, "failed to update config: %w", err)
+		}
+		saved += len(games)
+		scanLogf("💾 Saved %d games from Ubisoft Connect\n", len(games))
+	}
+
+	scanLogf("✅ Config updated with %d games\n", saved)
+	scanResultLine("saved %d games across %d libraries to %s", saved, len(libraries), configFile)
+	return nil
+}
+
+// noticef prints an informational side message. It stays out of --quiet,
+// --json and --once output, and out of pipes, so scripts only see results.
+func noticef(format string, args ...interface{}) {
+	if quiet || jsonOutput || scanOnce || !isConsole(os.Stdout) {
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// scanLogf prints scan progress, which --once suppresses in favour of a single result line
+func scanLogf(format string, args ...interface{}) {
+	if !scanOnce {
+		fmt.Printf(format, args...)
+	}
+}
+
+// scanLogln is the Println counterpart of scanLogf
+func scanLogln(args ...interface{}) {
+	if !scanOnce {
+		fmt.Println(args...)
+	}
+}
+
+// scanResultLine prints the one-line summary emitted by --once
+func scanResultLine(format string, args ...interface{}) {
+	if scanOnce {
+		fmt.Printf("scan-steam: "+format+"\n", args...)
+	}
+}
+
+func runAddGame(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("add-game"); err != nil {
+		return err
+	}
+
+	updater := NewConfigUpdater(configFile)
+	
+	if err := updater.AddCustomGame(gameName, gameExe, gamePath); err != nil {
+		return newCommandError(codeConfigError, "failed to add game: %w", err)
+	}
+	
+	fmt.Printf("✅ Added custom game: %s (%s)\n", gameName, gameExe)
+	return nil
+}
+
+func runRemoveGame(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("remove-game"); err != nil {
+		return err
 	}
-	
-	fmt.Printf("✅ Added custom game: %s (%s)\n", gameName, gameExe)
-}
 
-func runRemoveGame(cmd *cobra.Command, args []string) {
 	updater := NewConfigUpdater(configFile)
 	
 	if err := updater.RemoveCustomGame(gameName); err != nil {
-		fmt.Printf("❌ Failed to remove game: %v\n", err)
-		os.Exit(1)
+		return newCommandError(codeConfigError, "failed to remove game: %w", err)
 	}
 	
 	fmt.Printf("✅ Removed custom game: %s\n", gameName)
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("profile use"); err != nil {
+		return err
+	}
+
+	if err := NewConfigUpdater(configFile).SetActiveProfile(args[0]); err != nil {
+		return newCommandError(codeConfigError, "failed to switch profile: %w", err)
+	}
+
+	if args[0] == "" {
+		fmt.Println("✅ Cleared the active profile")
+	} else {
+		fmt.Printf("✅ Active profile: %s (applies on next start)\n", args[0])
+	}
+	return nil
+}
+
+func runProcesses(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	watcher := NewGameWatcher(config, nil)
+	processes, err := watcher.getRunningProcesses()
+	if err != nil {
+		return newCommandError(codeUnknown, "failed to list processes: %w", err)
+	}
+
+	games := make(map[string]watchedGame)
+	for _, game := range configuredGames(config) {
+		games[matchKey(config, game.Executable)] = game
+	}
+
+	keys := make(map[string]bool)
+	for _, process := range processes {
+		keys[matchKey(config, process)] = true
+	}
+
+	names := make([]string, 0, len(keys))
+	filter := strings.ToLower(procFilter)
+	for key := range keys {
+		if filter == "" || strings.Contains(strings.ToLower(key), filter) {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if game, ok := games[name]; ok {
+			fmt.Printf("  %s  ← %s [%s]\n", name, game.Name, game.Source)
+		} else {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	fmt.Printf("📊 %d processes shown\n", len(names))
+	return nil
+}
+
+func runLearn(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("learn"); err != nil {
+		return err
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	learner := NewLearner(config, NewConfigUpdater(configFile), learnFor, learnAutoAdd, learnAnyWin)
+
+	logInfof("🧠 Learn mode: apps in front for %s will be offered as games (Ctrl+C to stop)\n", learnFor)
+
+	ticker := time.NewTicker(config.CheckInterval)
+	defer ticker.Stop()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(c)
+
+	for {
+		select {
+		case <-ticker.C:
+			learner.Check()
+		case <-c:
+			logInfof("🛑 Learn mode stopped\n")
+			return nil
+		}
+	}
 }
 
-func runListGames(cmd *cobra.Command, args []string) {
-	config, err := LoadConfig(configFile)
+func runScanInstalled(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("scan-installed"); err != nil {
+		return err
+	}
+
+	config, err := loadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, game := range configuredGames(config) {
+		known[matchKey(config, game.Executable)] = true
+	}
+
+	fmt.Println("🔍 Looking for games in the Windows uninstall list...")
+	updater := NewConfigUpdater(configFile)
+	input := bufio.NewReader(os.Stdin)
+	found, added := 0, 0
+	detector := NewUninstallDetector(installedMB)
+	detector.scanner.SetSearchSubdirs(config.ExeSearchSubdirs)
+	for _, app := range detector.FindApps() {
+		if known[matchKey(config, app.Executable)] {
+			continue
+		}
+		found++
+
+		if app.SizeMB > 0 {
+			fmt.Printf("🆕 %s (%s, %.1f GB) [%s]\n", app.Name, app.Executable, float64(app.SizeMB)/1024, app.Path)
+		} else {
+			fmt.Printf("🆕 %s (%s) [%s]\n", app.Name, app.Executable, app.Path)
+		}
+		if dryRun {
+			continue
+		}
+		if !learnAutoAdd {
+			fmt.Printf("   Add it as a custom game? [y/N] ")
+			answer, _ := input.ReadString('\n')
+			if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+				continue
+			}
+		}
+
+		if err := updater.AddCustomGame(app.Name, app.Executable, app.Path); err != nil {
+			fmt.Printf("❌ Failed to add %s: %v\n", app.Executable, err)
+			continue
+		}
+		known[matchKey(config, app.Executable)] = true
+		added++
+	}
+
+	fmt.Printf("📊 %d candidate(s) found, %d added\n", found, added)
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	value, err := GetConfigField(config, args[0])
+	if err != nil {
+		return newCommandError(codeInvalidArgument, "%w", err)
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("config set"); err != nil {
+		return err
+	}
+
+	if err := NewConfigUpdater(configFile).SetField(args[0], args[1]); err != nil {
+		return newCommandError(codeConfigError, "failed to set %s: %w", args[0], err)
+	}
+
+	fmt.Printf("✅ Set %s = %s\n", args[0], args[1])
+	return nil
+}
+
+func runImportConfig(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("import-config"); err != nil {
+		return err
+	}
+
+	updater := NewConfigUpdater(configFile)
+	summary, err := updater.ImportCustomGames(args[0], onConflict)
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to import games: %w", err)
+	}
+
+	fmt.Printf("✅ Imported games from %s (%s)\n", args[0], onConflict)
+	fmt.Printf("  Added: %d, updated: %d, skipped: %d\n", summary.Added, summary.Updated, summary.Skipped)
+	return nil
+}
+
+func runEnableGame(cmd *cobra.Command, args []string) error {
+	return setGameEnabled(args[0], true)
+}
+
+func runDisableGame(cmd *cobra.Command, args []string) error {
+	return setGameEnabled(args[0], false)
+}
+
+// setGameEnabled toggles a game for enable-game/disable-game
+func setGameEnabled(name string, enabled bool) error {
+	if err := requireConfigFile("enable-game/disable-game"); err != nil {
+		return err
+	}
+
+	gameName, err := NewConfigUpdater(configFile).SetGameEnabled(name, enabled)
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to update game: %w", err)
+	}
+
+	if enabled {
+		fmt.Printf("✅ Enabled %s\n", gameName)
+	} else {
+		fmt.Printf("⏸️ Disabled %s (run enable-game to turn it back on)\n", gameName)
+	}
+	return nil
+}
+
+func runExportGames(cmd *cobra.Command, args []string) error {
+	count, err := NewConfigUpdater(configFile).ExportGames(args[0])
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to export games: %w", err)
+	}
+
+	fmt.Printf("📤 Exported %d game(s) to %s\n", count, args[0])
+	return nil
+}
+
+func runImportGames(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("import-games"); err != nil {
+		return err
+	}
+
+	summary, err := NewConfigUpdater(configFile).ImportGames(args[0], onConflict)
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to import games: %w", err)
+	}
+
+	fmt.Printf("✅ Imported games from %s (%s)\n", args[0], onConflict)
+	fmt.Printf("  Added: %d, updated: %d, skipped: %d\n", summary.Added, summary.Updated, summary.Skipped)
+	return nil
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	if err := requireConfigFile("undo"); err != nil {
+		return err
+	}
+
+	updater := NewConfigUpdater(configFile)
+	current, restored, err := updater.Undo()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to undo: %w", err)
+	}
+
+	fmt.Println("↩️ Reverted the last config change")
+
+	added, removed := DiffGames(current, restored)
+	for _, name := range added {
+		fmt.Printf("  + %s\n", name)
+	}
+	for _, name := range removed {
+		fmt.Printf("  - %s\n", name)
+	}
+	if current.DefaultPollingRate != restored.DefaultPollingRate || current.GamePollingRate != restored.GamePollingRate {
+		fmt.Printf("  rates: %d/%dHz -> %d/%dHz (default/game)\n",
+			current.DefaultPollingRate, current.GamePollingRate, restored.DefaultPollingRate, restored.GamePollingRate)
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Println("  (no games added or removed)")
+	}
+
+	fmt.Println("💡 Run undo again to redo the change")
+	return nil
+}
+
+func runListGames(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
 	}
 
 	fmt.Println("🎮 Configured Games:")
-	
+
+	// Entries for an executable listed in several sections are watched once, so list them once
+	duplicates := duplicateKeys(config)
+	for _, d := range duplicateGames(config) {
+		logDebugf("🔁 Not listing %s (%s) again, it's watched as %s (%s)\n", d.Duplicate.Name, d.Duplicate.Source, d.Kept.Name, d.Kept.Source)
+	}
+
 	// Show detected Steam games
-	if len(config.DetectedGames) > 0 {
+	if games := uniqueGames(config.DetectedGames, sourceSteam, duplicates); len(games) > 0 {
 		fmt.Println("\n📚 Steam Games:")
-		for _, game := range config.DetectedGames {
+		for _, game := range games {
 			if game.SizeMB > 0 {
-				fmt.Printf("  - %s (%s, %.1f GB)\n", game.Name, game.Executable, float64(game.SizeMB)/1024)
+				fmt.Printf("  - %s (%s, %.1f GB)%s%s\n", game.Name, game.Executable, float64(game.SizeMB)/1024, lastSeenLabel(game.LastSeen), disabledLabel(game.Enabled))
 			} else {
-				fmt.Printf("  - %s (%s)\n", game.Name, game.Executable)
+				fmt.Printf("  - %s (%s)%s%s\n", game.Name, game.Executable, lastSeenLabel(game.LastSeen), disabledLabel(game.Enabled))
 			}
 		}
 	}
 	
+	// Show detected Epic games
+	if games := uniqueGames(config.DetectedEpicGames, sourceEpic, duplicates); len(games) > 0 {
+		fmt.Println("\n🟦 Epic Games:")
+		for _, game := range games {
+			fmt.Printf("  - %s (%s)%s%s\n", game.Name, game.Executable, lastSeenLabel(game.LastSeen), disabledLabel(game.Enabled))
+		}
+	}
+
+	// Show detected Ubisoft games
+	if games := uniqueGames(config.DetectedUbisoftGames, sourceUbisoft, duplicates); len(games) > 0 {
+		fmt.Println("\n🟪 Ubisoft Connect Games:")
+		for _, game := range games {
+			fmt.Printf("  - %s (%s)%s%s\n", game.Name, game.Executable, lastSeenLabel(game.LastSeen), disabledLabel(game.Enabled))
+		}
+	}
+
 	// Show custom games
 	if len(config.CustomGames) > 0 {
 		fmt.Println("\n🛠️ Custom Games:")
 		for _, game := range config.CustomGames {
+			if duplicates[duplicateKey(sourceCustom, game.Name, game.Executable)] {
+				continue
+			}
 			if game.Path != "" {
-				fmt.Printf("  - %s (%s) [%s]\n", game.Name, game.Executable, game.Path)
+				fmt.Printf("  - %s (%s) [%s]%s%s\n", game.Name, game.Executable, game.Path, lastSeenLabel(game.LastSeen), disabledLabel(game.Enabled))
 			} else {
-				fmt.Printf("  - %s (%s)\n", game.Name, game.Executable)
+				fmt.Printf("  - %s (%s)%s%s\n", game.Name, game.Executable, lastSeenLabel(game.LastSeen), disabledLabel(game.Enabled))
 			}
 		}
 	}
@@ -407,10 +1890,194 @@ func runListGames(cmd *cobra.Command, args []string) {
 	}
 
 	// Summary
-	total := len(config.DetectedGames) + len(config.CustomGames) + len(config.Games)
+	total := config.gameCount() - len(duplicates)
 	fmt.Printf("\n📊 Total: %d games configured\n", total)
 	
 	if config.Steam != nil && !config.Steam.LastScan.IsZero() {
 		fmt.Printf("🕐 Last Steam scan: %s\n", config.Steam.LastScan.Format("2006-01-02 15:04:05"))
 	}
+	return nil
+}
+
+// uniqueGames drops the detected games of a section that duplicate an entry
+// listed elsewhere
+func uniqueGames(games []Game, source string, duplicates map[string]bool) []Game {
+	unique := make([]Game, 0, len(games))
+	for _, game := range games {
+		if !duplicates[duplicateKey(source, game.Name, game.Executable)] {
+			unique = append(unique, game)
+		}
+	}
+	return unique
+}
+
+// lastSeenLabel describes when a game last ran, flagging ones idle long enough to prune
+func lastSeenLabel(at time.Time) string {
+	if at.IsZero() {
+		return " - never seen"
+	}
+	label := " - last seen " + at.Format("2006-01-02")
+	if time.Since(at) > 90*24*time.Hour {
+		label += " 💤"
+	}
+	return label
+}
+
+// disabledLabel marks games turned off with disable-game in list-games output
+func disabledLabel(enabled *bool) string {
+	if gameEnabled(enabled) {
+		return ""
+	}
+	return " [disabled]"
+}
+
+// libraryStatus is one row of list-libraries output
+type libraryStatus struct {
+	Label      string `json:"label"`
+	Path       string `json:"path"`
+	Accessible bool   `json:"accessible"`
+	Games      int    `json:"games"`
+}
+
+func runListLibraries(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return newCommandError(codeConfigError, "failed to load config: %w", err)
+	}
+
+	var libraries []Library
+	if config.Steam != nil {
+		libraries = config.Steam.Libraries
+	}
+
+	// Detected games record the label of the library they were found in
+	counts := make(map[string]int)
+	for _, game := range config.DetectedGames {
+		counts[game.Library]++
+	}
+
+	detector := NewSteamDetector(config)
+	statuses := make([]libraryStatus, 0, len(libraries))
+	for _, lib := range libraries {
+		statuses = append(statuses, libraryStatus{
+			Label:      lib.Label,
+			Path:       lib.Path,
+			Accessible: detector.validateLibraryPath(lib.Path),
+			Games:      counts[lib.Label],
+		})
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return newCommandError(codeUnknown, "failed to encode libraries: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("📚 No Steam libraries configured - run scan-steam first")
+		return nil
+	}
+
+	fmt.Println("📚 Steam Libraries:")
+	for _, status := range statuses {
+		state := "✅"
+		if !status.Accessible {
+			state = "❌ inaccessible"
+		}
+		fmt.Printf("  - %s: %s (%d games) %s\n", status.Label, status.Path, status.Games, state)
+	}
+	return nil
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	if watchConfig && noConfig {
+		return newCommandError(codeInvalidArgument, "--watch-config needs a config file and can't be used with --no-config")
+	}
+
+	valid := validateConfigFile()
+	if !watchConfig {
+		if !valid {
+			return newCommandError(codeConfigError, "config %s is invalid", configFile)
+		}
+		return nil
+	}
+
+	fmt.Printf("👀 Watching %s for changes (Ctrl+C to stop)...\n", configFile)
+
+	lastModTime := configModTime(configFile)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	for {
+		select {
+		case <-ticker.C:
+			modTime := configModTime(configFile)
+			if modTime.Equal(lastModTime) {
+				continue
+			}
+			lastModTime = modTime
+
+			fmt.Printf("\n🔄 Config changed at %s\n", time.Now().Format("15:04:05"))
+			validateConfigFile()
+		case <-c:
+			fmt.Println("\n👋 Stopped watching config")
+			return nil
+		}
+	}
+}
+
+// validateConfigFile loads and validates the config, printing the result
+func validateConfigFile() bool {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return false
+	}
+
+	problems := ValidateConfig(config)
+	if len(problems) > 0 {
+		fmt.Printf("❌ Config has %d problem(s):\n", len(problems))
+		for _, problem := range problems {
+			fmt.Printf("  - %v\n", problem)
+		}
+		return false
+	}
+
+	totalGames := config.gameCount() - len(duplicateGames(config))
+	fmt.Printf("✅ Config is valid (%d games)\n", totalGames)
+	return true
+}
+
+// configModTime returns the config file's modification time, or zero if it can't be read
+func configModTime(filename string) time.Time {
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return time.Time{}
+	}
+	return stat.ModTime()
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	var err error
+	switch args[0] {
+	case "powershell":
+		err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+	case "bash":
+		err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		err = rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		err = rootCmd.GenFishCompletion(os.Stdout, true)
+	}
+
+	if err != nil {
+		return newCommandError(codeUnknown, "failed to generate %s completion: %w", args[0], err)
+	}
+	return nil
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// manifestCacheFile is the sidecar next to the config that remembers scan results
+const manifestCacheFile = "steam_manifest_cache.json"
+
+// manifestCache remembers what each appmanifest resolved to, keyed by path, so
+// scans skip parsing and the executable search for manifests that haven't
+// changed. Steam rewrites the manifest on every install and update.
+type manifestCache struct {
+	path     string
+	settings string // scanSettings the entries were resolved with
+
+	mu      sync.Mutex
+	entries map[string]manifestCacheEntry
+	seen    map[string]bool // Manifests visited this scan; the rest are dropped on save
+	dirty   bool
+	hits    int
+}
+
+// manifestCacheEntry is a manifest's file version and the game it resolved to
+type manifestCacheEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Game    Game      `json:"game"`
+}
+
+// manifestCacheData is the on-disk format. Settings fingerprints the scanner
+// options that affect results; entries resolved with other options are discarded.
+type manifestCacheData struct {
+	Settings string                        `json:"settings"`
+	Entries  map[string]manifestCacheEntry `json:"entries"`
+}
+
+// manifestCachePath places the cache next to the config file
+func manifestCachePath() string {
+	return filepath.Join(filepath.Dir(configFile), manifestCacheFile)
+}
+
+// loadManifestCache reads the cache file. A missing, unreadable or outdated
+// cache simply starts empty, as does a fresh one (scan-steam --force).
+func loadManifestCache(path, settings string, fresh bool) *manifestCache {
+	cache := &manifestCache{
+		path:     path,
+		settings: settings,
+		entries:  make(map[string]manifestCacheEntry),
+		seen:     make(map[string]bool),
+	}
+	if fresh {
+		cache.dirty = true
+		return cache
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+
+	var data manifestCacheData
+	if err := json.Unmarshal(content, &data); err != nil {
+		logDebugf("⚠️ Ignoring unreadable manifest cache %s: %v\n", path, err)
+		return cache
+	}
+	if data.Settings != settings {
+		logDebugf("🗑️ Scan settings changed, discarding manifest cache\n")
+		cache.dirty = true
+		return cache
+	}
+	if data.Entries != nil {
+		cache.entries = data.Entries
+	}
+	return cache
+}
+
+// lookup returns the cached game for an unchanged manifest
+func (c *manifestCache) lookup(manifestPath string, info os.FileInfo) (Game, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seen[manifestPath] = true
+	entry, ok := c.entries[manifestPath]
+	if !ok || !entry.ModTime.Equal(info.ModTime()) || entry.Size != info.Size() {
+		return Game{}, false
+	}
+	c.hits++
+	return entry.Game, true
+}
+
+// store records the game a manifest resolved to
+func (c *manifestCache) store(manifestPath string, info os.FileInfo, game Game) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seen[manifestPath] = true
+	c.entries[manifestPath] = manifestCacheEntry{ModTime: info.ModTime(), Size: info.Size(), Game: game}
+	c.dirty = true
+}
+
+// save drops manifests that no longer exist and writes the cache if anything changed
+func (c *manifestCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for manifestPath := range c.entries {
+		if !c.seen[manifestPath] {
+			delete(c.entries, manifestPath)
+			c.dirty = true
+		}
+	}
+	logDebugf("🗃️ Manifest cache: %d of %d manifests unchanged\n", c.hits, len(c.seen))
+	if !c.dirty {
+		return nil
+	}
+
+	content, err := json.Marshal(manifestCacheData{Settings: c.settings, Entries: c.entries})
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// scanSettings fingerprints the scanner options that change what a manifest resolves to
+func scanSettings(extraSubdirs []string, nameOverrides map[string]string) string {
+	overrides := make([]string, 0, len(nameOverrides))
+	for appID, name := range nameOverrides {
+		overrides = append(overrides, appID+"="+name)
+	}
+	sort.Strings(overrides)
+	return strings.Join(extraSubdirs, "|") + "#" + strings.Join(overrides, "|")
+}
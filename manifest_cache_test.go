@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFakeLibrary creates a Steam library holding count games
+func writeFakeLibrary(t testing.TB, count int) Library {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "SteamLibrary")
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("Game%d", i)
+		writeFakeGame(t, path, fmt.Sprint(1000+i), name, name, name+".exe")
+	}
+	return Library{Path: path, Label: "Games"}
+}
+
+// scanWithCache runs a scan that reads and saves the cache at cachePath
+func scanWithCache(t testing.TB, library Library, cachePath string, fresh bool) (*GameScanner, []Game) {
+	t.Helper()
+	scanner := NewGameScanner([]Library{library}, 1)
+	scanner.UseManifestCache(cachePath, fresh)
+	games, err := scanner.ScanAllLibraries()
+	if err != nil {
+		t.Fatalf("ScanAllLibraries: %v", err)
+	}
+	return scanner, games
+}
+
+// TestManifestCacheHitsAndMisses checks a second scan reuses every unchanged
+// manifest and returns the same games
+func TestManifestCacheHitsAndMisses(t *testing.T) {
+	library := writeFakeLibrary(t, 3)
+	cachePath := filepath.Join(t.TempDir(), manifestCacheFile)
+
+	cold, first := scanWithCache(t, library, cachePath, false)
+	if cold.cache.hits != 0 {
+		t.Errorf("first scan had %d cache hits, want 0", cold.cache.hits)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("cache not saved: %v", err)
+	}
+
+	warm, second := scanWithCache(t, library, cachePath, false)
+	if warm.cache.hits != len(first) {
+		t.Errorf("second scan had %d cache hits, want %d", warm.cache.hits, len(first))
+	}
+	if len(second) != len(first) {
+		t.Errorf("second scan found %d games, want %d", len(second), len(first))
+	}
+
+	forced, _ := scanWithCache(t, library, cachePath, true)
+	if forced.cache.hits != 0 {
+		t.Errorf("--force scan had %d cache hits, want 0", forced.cache.hits)
+	}
+}
+
+// TestManifestCacheInvalidation checks changed manifests, changed scan settings
+// and removed manifests aren't served from the cache
+func TestManifestCacheInvalidation(t *testing.T) {
+	library := writeFakeLibrary(t, 3)
+	cachePath := filepath.Join(t.TempDir(), manifestCacheFile)
+	scanWithCache(t, library, cachePath, false)
+
+	// An update rewrites the manifest
+	updated := filepath.Join(library.Path, "steamapps", "appmanifest_1000.acf")
+	writeFakeGame(t, library.Path, "1000", "Game0 Remastered", "Game0", "Game0.exe")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(updated, later, later); err != nil {
+		t.Fatal(err)
+	}
+	scanner, games := scanWithCache(t, library, cachePath, false)
+	if scanner.cache.hits != 2 {
+		t.Errorf("scan after an update had %d cache hits, want 2", scanner.cache.hits)
+	}
+	found := false
+	for _, game := range games {
+		found = found || game.Name == "Game0 Remastered"
+	}
+	if !found {
+		t.Errorf("updated manifest served from the cache: %+v", games)
+	}
+
+	// Other scanner options resolve manifests differently
+	scanner = NewGameScanner([]Library{library}, 1)
+	scanner.SetSearchSubdirs([]string{"bin"})
+	scanner.UseManifestCache(cachePath, false)
+	if _, err := scanner.ScanAllLibraries(); err != nil {
+		t.Fatalf("ScanAllLibraries: %v", err)
+	}
+	if scanner.cache.hits != 0 {
+		t.Errorf("scan with other settings had %d cache hits, want 0", scanner.cache.hits)
+	}
+
+	// Uninstalled games drop out of the cache on save
+	if err := os.Remove(updated); err != nil {
+		t.Fatal(err)
+	}
+	scanner = NewGameScanner([]Library{library}, 1)
+	scanner.SetSearchSubdirs([]string{"bin"})
+	scanner.UseManifestCache(cachePath, false)
+	if _, err := scanner.ScanAllLibraries(); err != nil {
+		t.Fatalf("ScanAllLibraries: %v", err)
+	}
+	reloaded := loadManifestCache(cachePath, scanSettings([]string{"bin"}, nil), false)
+	if _, ok := reloaded.entries[updated]; ok || len(reloaded.entries) != 2 {
+		t.Errorf("cache kept %d entries after an uninstall, want 2 without %s", len(reloaded.entries), updated)
+	}
+}
+
+// BenchmarkManifestCache compares a scan that resolves every manifest with one
+// served from a warm cache
+func BenchmarkManifestCache(b *testing.B) {
+	library := writeFakeLibrary(b, 50)
+	cachePath := filepath.Join(b.TempDir(), manifestCacheFile)
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			scanWithCache(b, library, cachePath, true)
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		scanWithCache(b, library, cachePath, false)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			scanWithCache(b, library, cachePath, false)
+		}
+	})
+}
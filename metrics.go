@@ -0,0 +1,58 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// WatcherMetrics is a point-in-time snapshot of the watcher's counters,
+// shaped for JSON so dashboards can scrape it without parsing logs
+type WatcherMetrics struct {
+	Checks      int64  `json:"checks"`
+	Switches    int64  `json:"switches"`
+	Reconciles  int64  `json:"reconciles"`
+	Reconnects  int64  `json:"reconnects"`
+	Errors      int64  `json:"errors"`
+	Uptime      string `json:"uptime"`
+	CurrentRate int    `json:"current_rate"`
+	CurrentGame string `json:"current_game,omitempty"`
+}
+
+// watcherCounters accumulates metrics; the watcher goroutine writes while
+// callers on other goroutines read snapshots
+type watcherCounters struct {
+	mu         sync.Mutex
+	started    time.Time
+	checks     int64
+	switches   int64
+	reconciles int64
+	reconnects int64
+	errors     int64
+}
+
+func (c *watcherCounters) add(counter *int64) {
+	c.mu.Lock()
+	*counter++
+	c.mu.Unlock()
+}
+
+// Metrics returns a snapshot of the watcher's counters and current state. It
+// may be called from any goroutine, e.g. the metrics pipe.
+func (gw *GameWatcher) Metrics() WatcherMetrics {
+	status := gw.currentStatus()
+	metrics := WatcherMetrics{CurrentRate: status.rate, CurrentGame: status.game}
+
+	gw.counters.mu.Lock()
+	defer gw.counters.mu.Unlock()
+
+	metrics.Checks = gw.counters.checks
+	metrics.Switches = gw.counters.switches
+	metrics.Reconciles = gw.counters.reconciles
+	metrics.Reconnects = gw.counters.reconnects
+	metrics.Errors = gw.counters.errors
+	if !gw.counters.started.IsZero() {
+		metrics.Uptime = time.Since(gw.counters.started).Round(time.Second).String()
+	}
+
+	return metrics
+}
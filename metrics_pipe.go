@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// metricsPipeName is the named pipe a running watcher serves its metrics on.
+// Named pipes are readable across sessions, so the metrics command also
+// reaches the watcher when it runs as the service.
+const metricsPipeName = `\\.\pipe\lamzu-automator-metrics`
+
+// metricsPipeStopWait bounds how long Stop waits for the server to notice
+const metricsPipeStopWait = time.Second
+
+// metricsServer answers every connection on the metrics pipe with one JSON
+// snapshot of the watcher's metrics, then hangs up
+type metricsServer struct {
+	watcher *GameWatcher
+	stopped atomic.Bool
+	done    chan struct{}
+}
+
+// serveMetrics starts serving the watcher's metrics on the pipe
+func serveMetrics(watcher *GameWatcher) *metricsServer {
+	s := &metricsServer{watcher: watcher, done: make(chan struct{})}
+	go s.run()
+	return s
+}
+
+func (s *metricsServer) run() {
+	defer close(s.done)
+
+	name, err := windows.UTF16PtrFromString(metricsPipeName)
+	if err != nil {
+		return
+	}
+
+	for !s.stopped.Load() {
+		// One instance at a time; a second watcher can't take over the name
+		pipe, err := windows.CreateNamedPipe(name,
+			windows.PIPE_ACCESS_OUTBOUND|windows.FILE_FLAG_FIRST_PIPE_INSTANCE,
+			windows.PIPE_TYPE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+			1, 4096, 0, 0, nil)
+		if err != nil {
+			logWarnf("⚠️ Metrics endpoint unavailable (is another instance running?): %v\n", err)
+			return
+		}
+
+		err = windows.ConnectNamedPipe(pipe, nil)
+		if err == nil || errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+			if !s.stopped.Load() {
+				s.answer(pipe)
+			}
+		} else {
+			logDebugf("⚠️ Metrics client failed to connect: %v\n", err)
+		}
+		windows.DisconnectNamedPipe(pipe)
+		windows.CloseHandle(pipe)
+	}
+}
+
+// answer writes one metrics snapshot to a connected client
+func (s *metricsServer) answer(pipe windows.Handle) {
+	data, err := json.Marshal(s.watcher.Metrics())
+	if err != nil {
+		logDebugf("⚠️ Failed to encode metrics: %v\n", err)
+		return
+	}
+
+	var written uint32
+	if err := windows.WriteFile(pipe, data, &written, nil); err != nil {
+		logDebugf("⚠️ Failed to send metrics: %v\n", err)
+		return
+	}
+	windows.FlushFileBuffers(pipe)
+}
+
+// Stop shuts the endpoint down. The server may be blocked waiting for a
+// client, so Stop connects to the pipe itself to release it.
+func (s *metricsServer) Stop() {
+	s.stopped.Store(true)
+
+	deadline := time.After(metricsPipeStopWait)
+	for {
+		if pipe, err := openMetricsPipe(); err == nil {
+			windows.CloseHandle(pipe)
+		}
+		select {
+		case <-s.done:
+			return
+		case <-deadline:
+			logDebugf("⚠️ Metrics endpoint did not stop within %s\n", metricsPipeStopWait)
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// openMetricsPipe connects to the metrics pipe, retrying briefly while the
+// server is answering another client
+func openMetricsPipe() (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(metricsPipeName)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		pipe, err := windows.CreateFile(name, windows.GENERIC_READ, 0, nil, windows.OPEN_EXISTING, 0, 0)
+		if errors.Is(err, windows.ERROR_PIPE_BUSY) && attempt < 20 {
+			time.Sleep(50 * time.Millisecond)
+			continue
+		}
+		return pipe, err
+	}
+}
+
+// readMetrics fetches the metrics of the running watcher over the pipe
+func readMetrics() (WatcherMetrics, error) {
+	pipe, err := openMetricsPipe()
+	if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+		return WatcherMetrics{}, fmt.Errorf("no running watcher found (start lamzu-automator or its service first)")
+	}
+	if err != nil {
+		return WatcherMetrics{}, fmt.Errorf("failed to connect to the watcher: %w", err)
+	}
+
+	file := os.NewFile(uintptr(pipe), metricsPipeName)
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return WatcherMetrics{}, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	var metrics WatcherMetrics
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return WatcherMetrics{}, fmt.Errorf("failed to decode metrics: %w", err)
+	}
+	return metrics, nil
+}
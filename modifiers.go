@@ -0,0 +1,57 @@
+package main
+
+import "fmt"
+
+// ModifierRule caps the selected polling rate while any of its processes run,
+// e.g. limiting USB interrupt load while OBS is encoding a stream
+type ModifierRule struct {
+	Name      string   `yaml:"name"`
+	Processes []string `yaml:"processes"`
+	MaxRate   int      `yaml:"max_rate"`
+}
+
+// activeModifier returns the matching rule with the lowest cap, or nil if none match
+func activeModifier(config *Config, processSet map[string]bool) *ModifierRule {
+	var active *ModifierRule
+
+	for i := range config.Modifiers {
+		rule := &config.Modifiers[i]
+		for _, process := range rule.Processes {
+			if !processSet[matchKey(config, process)] {
+				continue
+			}
+			if active == nil || rule.MaxRate < active.MaxRate {
+				active = rule
+			}
+			break
+		}
+	}
+
+	return active
+}
+
+// pausingProcess returns the first pause_during process that is running, or ""
+func pausingProcess(config *Config, processSet map[string]bool) string {
+	for _, process := range config.PauseDuring {
+		if processSet[matchKey(config, process)] {
+			return process
+		}
+	}
+	return ""
+}
+
+// validateModifiers reports modifier rules the watcher can't apply
+func validateModifiers(rules []ModifierRule) []error {
+	var problems []error
+
+	for i, rule := range rules {
+		if len(rule.Processes) == 0 {
+			problems = append(problems, fmt.Errorf("modifiers[%d] (%s): no trigger processes", i, rule.Name))
+		}
+		if _, ok := pollingRateMap[rule.MaxRate]; !ok {
+			problems = append(problems, fmt.Errorf("modifiers[%d] (%s): unsupported max_rate %d", i, rule.Name, rule.MaxRate))
+		}
+	}
+
+	return problems
+}
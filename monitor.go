@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"syscall"
+	"unsafe"
+)
+
+// RefreshRule selects a rate while the game's monitor refreshes at MinRefresh Hz or faster
+type RefreshRule struct {
+	MinRefresh int `yaml:"min_refresh"`
+	Rate       int `yaml:"rate"`
+}
+
+var (
+	procMonitorFromWindow    = user32.NewProc("MonitorFromWindow")
+	procGetMonitorInfoW      = user32.NewProc("GetMonitorInfoW")
+	procEnumDisplaySettingsW = user32.NewProc("EnumDisplaySettingsW")
+)
+
+const (
+	monitorDefaultToNearest = 2
+	enumCurrentSettings     = 0xFFFFFFFF
+)
+
+type monitorInfoEx struct {
+	CbSize  uint32
+	Monitor rect
+	Work    rect
+	Flags   uint32
+	Device  [32]uint16
+}
+
+// devMode mirrors DEVMODEW with the display variant of its unions
+type devMode struct {
+	DeviceName       [32]uint16
+	SpecVersion      uint16
+	DriverVersion    uint16
+	Size             uint16
+	DriverExtra      uint16
+	Fields           uint32
+	PositionX        int32
+	PositionY        int32
+	DisplayOrient    uint32
+	DisplayFixed     uint32
+	Color            int16
+	Duplex           int16
+	YResolution      int16
+	TTOption         int16
+	Collate          int16
+	FormName         [32]uint16
+	LogPixels        uint16
+	BitsPerPel       uint32
+	PelsWidth        uint32
+	PelsHeight       uint32
+	DisplayFlags     uint32
+	DisplayFrequency uint32
+	ICMMethod        uint32
+	ICMIntent        uint32
+	MediaType        uint32
+	DitherType       uint32
+	Reserved1        uint32
+	Reserved2        uint32
+	PanningWidth     uint32
+	PanningHeight    uint32
+}
+
+// refreshRateRate picks the tier for the highest min_refresh the monitor meets;
+// ok is false when no rule covers it
+func refreshRateRate(rules []RefreshRule, refresh int) (int, bool) {
+	sorted := append([]RefreshRule(nil), rules...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MinRefresh > sorted[j].MinRefresh })
+
+	for _, rule := range sorted {
+		if refresh >= rule.MinRefresh {
+			return rule.Rate, true
+		}
+	}
+	return 0, false
+}
+
+// validateRefreshRules reports tiers with unusable refresh rates or polling rates
+func validateRefreshRules(field string, rules []RefreshRule) []error {
+	var problems []error
+	for i, rule := range rules {
+		if rule.MinRefresh <= 0 {
+			problems = append(problems, fmt.Errorf("%s.refresh_rates[%d]: min_refresh must be greater than zero", field, i))
+		}
+		if _, ok := pollingRateMap[rule.Rate]; !ok {
+			problems = append(problems, fmt.Errorf("%s.refresh_rates[%d]: unsupported rate %d", field, i, rule.Rate))
+		}
+	}
+	return problems
+}
+
+// monitorRefreshRate returns the refresh rate of the monitor the executable's
+// main window is mostly on
+func monitorRefreshRate(executable string) (int, error) {
+	hwnd, _, err := gameWindow(executable)
+	if err != nil {
+		return 0, err
+	}
+
+	monitor, _, _ := procMonitorFromWindow.Call(hwnd, monitorDefaultToNearest)
+	if monitor == 0 {
+		return 0, fmt.Errorf("no monitor found for %s window", executable)
+	}
+
+	info := monitorInfoEx{CbSize: uint32(unsafe.Sizeof(monitorInfoEx{}))}
+	if ret, _, err := procGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&info))); ret == 0 {
+		return 0, fmt.Errorf("failed to get monitor info: %v", err)
+	}
+
+	mode := devMode{Size: uint16(unsafe.Sizeof(devMode{}))}
+	if ret, _, err := procEnumDisplaySettingsW.Call(uintptr(unsafe.Pointer(&info.Device[0])), enumCurrentSettings, uintptr(unsafe.Pointer(&mode))); ret == 0 {
+		return 0, fmt.Errorf("failed to read display settings for %s: %v", syscall.UTF16ToString(info.Device[:]), err)
+	}
+
+	// 0 and 1 mean "hardware default" rather than a real frequency
+	if mode.DisplayFrequency <= 1 {
+		return 0, fmt.Errorf("display %s doesn't report its refresh rate", syscall.UTF16ToString(info.Device[:]))
+	}
+
+	return int(mode.DisplayFrequency), nil
+}
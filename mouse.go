@@ -1,13 +1,30 @@
 package main
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 const (
 	LAMZU_VID        = 0x373E
 	LAMZU_PID        = 0x001E
 	INTERFACE_NUMBER = 2
 	REPORT_SIZE      = 65
+
+	// minReportSize covers the report ID, command bytes and the rate byte at offset 8
+	minReportSize = 9
 )
 
-var pollingRateMap = map[int]byte{
+// defaultPollingRateMap is the rate byte each polling rate uses on the LAMZU
+// firmware this tool was written against
+var defaultPollingRateMap = map[int]byte{
 	500:  2,
 	1000: 1,
 	2000: 32,
@@ -15,25 +32,554 @@ var pollingRateMap = map[int]byte{
 	8000: 128,
 }
 
+// pollingRateMap is the effective rate -> byte mapping: the defaults merged
+// with device.rate_bytes
+var pollingRateMap = mergeRateBytes(nil)
+
+// mergeRateBytes returns the default rate map with overrides applied on top
+func mergeRateBytes(overrides map[int]byte) map[int]byte {
+	merged := make(map[int]byte, len(defaultPollingRateMap)+len(overrides))
+	for rate, value := range defaultPollingRateMap {
+		merged[rate] = value
+	}
+	for rate, value := range overrides {
+		merged[rate] = value
+	}
+	return merged
+}
+
+// applyRateBytes makes device.rate_bytes the effective rate map
+func applyRateBytes(overrides map[int]byte) {
+	pollingRateMap = mergeRateBytes(overrides)
+}
+
+// validateRateBytes checks that overridden rates are positive and that no two
+// rates in the merged map share a byte, which would make read-back ambiguous
+func validateRateBytes(overrides map[int]byte) []error {
+	var problems []error
+	for rate := range overrides {
+		if rate <= 0 {
+			problems = append(problems, fmt.Errorf("device.rate_bytes: invalid rate %d", rate))
+		}
+	}
+
+	owner := make(map[byte]int)
+	merged := mergeRateBytes(overrides)
+	rates := make([]int, 0, len(merged))
+	for rate := range merged {
+		rates = append(rates, rate)
+	}
+	sort.Ints(rates)
+	for _, rate := range rates {
+		value := merged[rate]
+		if other, taken := owner[value]; taken {
+			problems = append(problems, fmt.Errorf("device.rate_bytes: %dHz and %dHz both use byte 0x%02X", other, rate, value))
+			continue
+		}
+		owner[value] = rate
+	}
+	return problems
+}
+
+// maxPollingRate is the max_polling_rate ceiling applied to every write; zero means no cap
+var maxPollingRate int
+
+// setMaxPollingRate sets the ceiling used by capPollingRate
+func setMaxPollingRate(rate int) {
+	maxPollingRate = rate
+}
+
+// capPollingRate clamps a requested rate to max_polling_rate
+func capPollingRate(rate int) int {
+	if maxPollingRate > 0 && rate > maxPollingRate {
+		return maxPollingRate
+	}
+	return rate
+}
+
+// DeviceProfile holds the per-model protocol details used to build HID reports.
+// Profiles for other models only need to override what differs from the default.
+type DeviceProfile struct {
+	Name     string `yaml:"name,omitempty"`
+	ReportID byte   `yaml:"report_id"` // First byte of every feature/output report
+	// ReportSize is the feature report length including the report ID; zero
+	// uses the size the device advertises, or REPORT_SIZE if it can't be read
+	ReportSize int `yaml:"report_size,omitempty"`
+	// ReadMethod selects how the current rate is read: "feature", "input", or
+	// empty to try the feature report first and fall back to an input report
+	ReadMethod string `yaml:"read_method,omitempty"`
+	// Slot is the onboard profile the rate is written to: a number, "auto" to
+	// use the slot the mouse reports as active, or empty for slot 1
+	Slot string `yaml:"slot,omitempty"`
+	// Ack describes the status byte the device reports after a command; nil
+	// means the model doesn't acknowledge and writes aren't read back
+	Ack *AckProfile `yaml:"ack,omitempty"`
+	// RateBytes overrides the byte sent for a polling rate, or adds a rate;
+	// rates not listed keep their default byte
+	RateBytes map[int]byte `yaml:"rate_bytes,omitempty"`
+	// VendorID, ProductID and ProductIDs are hex USB IDs ("0x373E") of the
+	// models to look for; empty uses LAMZU_VID / LAMZU_PID. Several product
+	// IDs let one config cover a lineup.
+	VendorID   string   `yaml:"vendor_id,omitempty"`
+	ProductID  string   `yaml:"product_id,omitempty"`
+	ProductIDs []string `yaml:"product_ids,omitempty"`
+}
+
+// parseUSBID parses a hex USB vendor or product ID, with or without 0x
+func parseUSBID(value string) (uint16, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(value), "0x"), "0X")
+	id, err := strconv.ParseUint(digits, 16, 16)
+	if digits == "" || err != nil {
+		return 0, fmt.Errorf("invalid USB ID %q (use 4 hex digits, e.g. 0x001E)", value)
+	}
+	return uint16(id), nil
+}
+
+// deviceIDs returns the vendor ID and product IDs discovery matches. Unset or
+// malformed values fall back to the defaults; validation reports the latter.
+func (p DeviceProfile) deviceIDs() (uint16, []uint16) {
+	vendorID := uint16(LAMZU_VID)
+	if p.VendorID != "" {
+		if id, err := parseUSBID(p.VendorID); err == nil {
+			vendorID = id
+		}
+	}
+
+	var productIDs []uint16
+	for _, value := range append([]string{p.ProductID}, p.ProductIDs...) {
+		if id, err := parseUSBID(value); value != "" && err == nil {
+			productIDs = append(productIDs, id)
+		}
+	}
+	if len(productIDs) == 0 {
+		productIDs = []uint16{LAMZU_PID}
+	}
+	return vendorID, productIDs
+}
+
+// AckProfile locates the status byte in the response report read after a write
+type AckProfile struct {
+	StatusOffset int  `yaml:"status_offset"`
+	OK           byte `yaml:"ok"`
+}
+
+// Ways a device can report its current polling rate
+const (
+	readMethodAuto    = ""
+	readMethodFeature = "feature"
+	readMethodInput   = "input"
+)
+
+// Onboard slot selection
+const (
+	defaultSlot = 1
+	slotAuto    = "auto"
+)
+
+// parseSlot validates a slot setting; "auto" and empty are resolved by the controller
+func parseSlot(slot string) (byte, error) {
+	switch slot {
+	case "":
+		return defaultSlot, nil
+	case slotAuto:
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(slot)
+	if err != nil || n < 0 || n > 255 {
+		return 0, fmt.Errorf("invalid slot %q (use a number from 0 to 255 or auto)", slot)
+	}
+	return byte(n), nil
+}
+
+// validateDeviceProfile reports settings in the device section the controller can't use
+func validateDeviceProfile(device *DeviceProfile) []error {
+	var problems []error
+	switch device.ReadMethod {
+	case readMethodAuto, readMethodFeature, readMethodInput:
+	default:
+		problems = append(problems, fmt.Errorf("device.read_method: unknown method %q (use feature or input)", device.ReadMethod))
+	}
+	if _, err := parseSlot(device.Slot); err != nil {
+		problems = append(problems, fmt.Errorf("device.slot: %w", err))
+	}
+	size := device.reportSize()
+	if device.ReportSize != 0 && size < minReportSize {
+		problems = append(problems, fmt.Errorf("device.report_size: must be at least %d bytes", minReportSize))
+	}
+	if ack := device.Ack; ack != nil && (ack.StatusOffset < 1 || ack.StatusOffset >= size) {
+		problems = append(problems, fmt.Errorf("device.ack.status_offset: must be between 1 and %d", size-1))
+	}
+	problems = append(problems, validateRateBytes(device.RateBytes)...)
+	if device.VendorID != "" {
+		if _, err := parseUSBID(device.VendorID); err != nil {
+			problems = append(problems, fmt.Errorf("device.vendor_id: %w", err))
+		}
+	}
+	if device.ProductID != "" {
+		if _, err := parseUSBID(device.ProductID); err != nil {
+			problems = append(problems, fmt.Errorf("device.product_id: %w", err))
+		}
+	}
+	for i, value := range device.ProductIDs {
+		if _, err := parseUSBID(value); err != nil {
+			problems = append(problems, fmt.Errorf("device.product_ids[%d]: %w", i, err))
+		}
+	}
+	return problems
+}
+
+// defaultDeviceProfile matches the LAMZU mice this tool was written against
+var defaultDeviceProfile = DeviceProfile{
+	Name:     "LAMZU",
+	ReportID: 0x00,
+}
+
+// reportSize returns the report length to allocate for this profile
+func (p DeviceProfile) reportSize() int {
+	if p.ReportSize == 0 {
+		return REPORT_SIZE
+	}
+	return p.ReportSize
+}
+
+// errSuperseded is returned by SetPollingRate when a newer request queued
+// behind it took its place: the device ends up at the newer rate, not this one
+var errSuperseded = errors.New("superseded by a newer rate request")
+
+// errUnsupportedRate is returned by SetPollingRate for a rate the device's
+// supported-rate probe found it doesn't accept; nothing is written
+var errUnsupportedRate = errors.New("not supported by this device")
+
 type MouseControllerInterface interface {
 	Close()
+	Reopen() error
+	SetProfile(profile DeviceProfile)
 	TestConnection() error
 	SetPollingRate(rate int) error
+	GetPollingRate() (int, error)
+	SupportedRates() ([]int, error)
+	DeviceInfo() DeviceInfo
+}
+
+// MultiMouseController drives several mice as one: commands go to every device
+// and reads come from the first. With discover and open set, mice connected
+// later join on the next rate change or reconnect, and mice that can't be
+// reopened are dropped.
+type MultiMouseController struct {
+	mu   sync.Mutex
+	mice []MouseControllerInterface
+
+	// discover lists the command interface paths of the connected devices and
+	// open opens one of them; both are nil when the set of mice is fixed
+	discover func() ([]string, error)
+	open     func(path string) (MouseControllerInterface, error)
+}
+
+// errNoMouse is returned once every device of a MultiMouseController is gone
+var errNoMouse = errors.New("no LAMZU device connected")
+
+// current returns a snapshot of the connected mice
+func (m *MultiMouseController) current() []MouseControllerInterface {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.mice)
+}
+
+// eachMouse runs fn on every device and joins the errors, labelled by device path
+func (m *MultiMouseController) eachMouse(fn func(mouse MouseControllerInterface) error) error {
+	mice := m.current()
+	if len(mice) == 0 {
+		return errNoMouse
+	}
+
+	var errs []error
+	for _, mouse := range mice {
+		if err := fn(mouse); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", mouse.DeviceInfo().Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rescan opens the devices connected since the last scan. Devices are told
+// apart by deviceKey, which survives the collection a path points at.
+func (m *MultiMouseController) rescan() {
+	if m.discover == nil {
+		return
+	}
+	paths, err := m.discover()
+	if err != nil {
+		logDebugf("⚠️ Device rescan failed: %v\n", err)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	known := make(map[string]bool, len(m.mice))
+	for _, mouse := range m.mice {
+		known[deviceKey(mouse.DeviceInfo().Path)] = true
+	}
+	for _, path := range paths {
+		if known[deviceKey(path)] {
+			continue
+		}
+		mouse, err := m.open(path)
+		if err != nil {
+			logDebugf("⚠️ Skipping LAMZU device %s: %v\n", path, err)
+			continue
+		}
+		known[deviceKey(path)] = true
+		m.mice = append(m.mice, mouse)
+		logInfof("🖱️ LAMZU device connected: %s\n", path)
+	}
+}
+
+func (m *MultiMouseController) Close() {
+	for _, mouse := range m.current() {
+		mouse.Close()
+	}
+}
+
+// Reopen reopens every device. When devices are discovered, one that can't be
+// reopened was unplugged and is dropped; if it comes back, or another mouse is
+// plugged in, the rescan picks it up.
+func (m *MultiMouseController) Reopen() error {
+	if m.discover == nil {
+		return m.eachMouse(MouseControllerInterface.Reopen)
+	}
+
+	m.mu.Lock()
+	kept := m.mice[:0]
+	for _, mouse := range m.mice {
+		path := mouse.DeviceInfo().Path
+		if err := mouse.Reopen(); err != nil {
+			logInfof("🔌 LAMZU device disconnected: %s (%v)\n", path, err)
+			mouse.Close()
+			continue
+		}
+		kept = append(kept, mouse)
+	}
+	m.mice = kept
+	m.mu.Unlock()
+
+	m.rescan()
+	if len(m.current()) == 0 {
+		return errNoMouse
+	}
+	return nil
+}
+
+func (m *MultiMouseController) SetProfile(profile DeviceProfile) {
+	for _, mouse := range m.current() {
+		mouse.SetProfile(profile)
+	}
+}
+
+func (m *MultiMouseController) TestConnection() error {
+	return m.eachMouse(MouseControllerInterface.TestConnection)
+}
+
+// SetPollingRate writes the rate to every device, including any connected
+// since the last write
+func (m *MultiMouseController) SetPollingRate(rate int) error {
+	m.rescan()
+	return m.eachMouse(func(mouse MouseControllerInterface) error {
+		return mouse.SetPollingRate(rate)
+	})
+}
+
+// GetPollingRate returns the first device's rate, or the first rate that
+// disagrees with it, so a read-back check notices any device drifting
+func (m *MultiMouseController) GetPollingRate() (int, error) {
+	mice := m.current()
+	if len(mice) == 0 {
+		return 0, errNoMouse
+	}
+	first, err := mice[0].GetPollingRate()
+	if err != nil {
+		return 0, err
+	}
+	for _, mouse := range mice[1:] {
+		if rate, err := mouse.GetPollingRate(); err == nil && rate != first {
+			return rate, nil
+		}
+	}
+	return first, nil
+}
+
+// SupportedRates returns the rates every device accepts
+func (m *MultiMouseController) SupportedRates() ([]int, error) {
+	mice := m.current()
+	if len(mice) == 0 {
+		return nil, errNoMouse
+	}
+	counts := make(map[int]int)
+	for _, mouse := range mice {
+		rates, err := mouse.SupportedRates()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", mouse.DeviceInfo().Path, err)
+		}
+		for _, rate := range rates {
+			counts[rate]++
+		}
+	}
+
+	var supported []int
+	for rate, count := range counts {
+		if count == len(mice) {
+			supported = append(supported, rate)
+		}
+	}
+	sort.Ints(supported)
+	return supported, nil
+}
+
+func (m *MultiMouseController) DeviceInfo() DeviceInfo {
+	mice := m.current()
+	if len(mice) == 0 {
+		return DeviceInfo{}
+	}
+	return mice[0].DeviceInfo()
+}
+
+// deviceCallTimeout bounds how long the watcher waits on one device call. A
+// variable so tests can shorten it.
+var deviceCallTimeout = 5 * time.Second
+
+// Errors from a timeoutMouse
+var (
+	errDeviceTimeout = errors.New("device did not respond in time")
+	errDeviceBusy    = errors.New("device is still busy with a call that timed out")
+)
+
+// timeoutMouse bounds the device calls the watcher makes, so a HID call that
+// never returns fails the tick instead of holding the watcher forever. The
+// stuck call is abandoned; until it returns, further calls fail right away
+// rather than queueing behind it.
+type timeoutMouse struct {
+	MouseControllerInterface
+	timeout time.Duration
+	busy    atomic.Bool
+}
+
+func newTimeoutMouse(mouse MouseControllerInterface, timeout time.Duration) *timeoutMouse {
+	return &timeoutMouse{MouseControllerInterface: mouse, timeout: timeout}
+}
+
+// call runs fn on its own goroutine and waits up to the timeout for it
+func (m *timeoutMouse) call(fn func() error) error {
+	if !m.busy.CompareAndSwap(false, true) {
+		return errDeviceBusy
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer m.busy.Store(false)
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(m.timeout):
+		return fmt.Errorf("%w (%s)", errDeviceTimeout, m.timeout)
+	}
+}
+
+func (m *timeoutMouse) Reopen() error {
+	return m.call(m.MouseControllerInterface.Reopen)
+}
+
+func (m *timeoutMouse) TestConnection() error {
+	return m.call(m.MouseControllerInterface.TestConnection)
+}
+
+func (m *timeoutMouse) SetPollingRate(rate int) error {
+	return m.call(func() error { return m.MouseControllerInterface.SetPollingRate(rate) })
+}
+
+func (m *timeoutMouse) GetPollingRate() (int, error) {
+	var rate int
+	err := m.call(func() error {
+		var err error
+		rate, err = m.MouseControllerInterface.GetPollingRate()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rate, nil
 }
 
+func (m *timeoutMouse) SupportedRates() ([]int, error) {
+	var rates []int
+	err := m.call(func() error {
+		var err error
+		rates, err = m.MouseControllerInterface.SupportedRates()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// DeviceInfo describes the connected device for display
+type DeviceInfo struct {
+	Path      string
+	VendorID  uint16
+	ProductID uint16
+	Version   uint16
+	Caps      *DeviceCaps // nil when the capabilities couldn't be read
+}
+
+// DeviceCaps is the HID capability summary of the opened interface
+type DeviceCaps struct {
+	UsagePage     uint16
+	Usage         uint16
+	InputLength   int
+	OutputLength  int
+	FeatureLength int
+}
+
+// sortedPollingRates returns the known polling rates in ascending order
+func sortedPollingRates() []int {
+	rates := make([]int, 0, len(pollingRateMap))
+	for rate := range pollingRateMap {
+		rates = append(rates, rate)
+	}
+	sort.Ints(rates)
+	return rates
+}
+
+// pollingRateFromByte maps a raw rate byte reported by the device back to Hz
+func pollingRateFromByte(value byte) (int, bool) {
+	for rate, rateValue := range pollingRateMap {
+		if rateValue == value {
+			return rate, true
+		}
+	}
+	return 0, false
+}
+
+// parsePollingRate returns the rate in s if the effective rate map knows it, or 0
 func parsePollingRate(s string) int {
-	switch s {
-	case "500":
-		return 500
-	case "1000":
-		return 1000
-	case "2000":
-		return 2000
-	case "4000":
-		return 4000
-	case "8000":
-		return 8000
-	default:
+	rate, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
 		return 0
 	}
+	if _, ok := pollingRateMap[rate]; !ok {
+		return 0
+	}
+	return rate
+}
+
+// pollingRateList formats the known rates for messages, e.g. "500, 1000, 2000"
+func pollingRateList() string {
+	rates := sortedPollingRates()
+	names := make([]string, len(rates))
+	for i, rate := range rates {
+		names[i] = strconv.Itoa(rate)
+	}
+	return strings.Join(names, ", ")
 }
@@ -3,9 +3,12 @@ package main
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -16,6 +19,10 @@ var (
 	hidD_GetHidGuid                 = hidDLL.NewProc("HidD_GetHidGuid")
 	hidD_GetAttributes              = hidDLL.NewProc("HidD_GetAttributes")
 	hidD_SetFeature                 = hidDLL.NewProc("HidD_SetFeature")
+	hidD_GetFeature                 = hidDLL.NewProc("HidD_GetFeature")
+	hidD_GetPreparsedData           = hidDLL.NewProc("HidD_GetPreparsedData")
+	hidD_FreePreparsedData          = hidDLL.NewProc("HidD_FreePreparsedData")
+	hidP_GetCaps                    = hidDLL.NewProc("HidP_GetCaps")
 	setupDiGetClassDevs             = setupapi.NewProc("SetupDiGetClassDevsW")
 	setupDiEnumDeviceInterfaces     = setupapi.NewProc("SetupDiEnumDeviceInterfaces")
 	setupDiGetDeviceInterfaceDetail = setupapi.NewProc("SetupDiGetDeviceInterfaceDetailW")
@@ -23,6 +30,8 @@ var (
 	createFile                      = kernel32.NewProc("CreateFileW")
 	closeHandle                     = kernel32.NewProc("CloseHandle")
 	writeFile                       = kernel32.NewProc("WriteFile")
+	readFile                        = kernel32.NewProc("ReadFile")
+	cancelIoEx                      = kernel32.NewProc("CancelIoEx")
 )
 
 const (
@@ -35,6 +44,7 @@ const (
 	FILE_SHARE_WRITE      = 0x00000002
 	OPEN_EXISTING         = 3
 	ERROR_NO_MORE_ITEMS   = 259
+	HIDP_STATUS_SUCCESS   = 0x00110000
 )
 
 type GUID struct {
@@ -56,6 +66,25 @@ type SP_DEVICE_INTERFACE_DETAIL_DATA struct {
 	DevicePath [1]uint16
 }
 
+type HIDP_CAPS struct {
+	Usage                     uint16
+	UsagePage                 uint16
+	InputReportByteLength     uint16
+	OutputReportByteLength    uint16
+	FeatureReportByteLength   uint16
+	Reserved                  [17]uint16
+	NumberLinkCollectionNodes uint16
+	NumberInputButtonCaps     uint16
+	NumberInputValueCaps      uint16
+	NumberInputDataIndices    uint16
+	NumberOutputButtonCaps    uint16
+	NumberOutputValueCaps     uint16
+	NumberOutputDataIndices   uint16
+	NumberFeatureButtonCaps   uint16
+	NumberFeatureValueCaps    uint16
+	NumberFeatureDataIndices  uint16
+}
+
 type HIDD_ATTRIBUTES struct {
 	Size          uint32
 	VendorID      uint16
@@ -63,31 +92,234 @@ type HIDD_ATTRIBUTES struct {
 	VersionNumber uint16
 }
 
+// Share modes accepted by --share-mode
+var shareModes = map[string]uint32{
+	"shared":    FILE_SHARE_READ | FILE_SHARE_WRITE,
+	"read":      FILE_SHARE_READ,
+	"exclusive": 0,
+}
+
+// deviceShareMode is the share mode requested when opening the device
+var deviceShareMode uint32 = FILE_SHARE_READ | FILE_SHARE_WRITE
+
+// deviceProfile is the protocol profile used for subsequent opens
+var deviceProfile = defaultDeviceProfile
+
 type WindowsMouseController struct {
 	handle     syscall.Handle
 	devicePath string
 	attributes HIDD_ATTRIBUTES
+	readOnly   bool
+	profile    DeviceProfile
+
+	// wantReadOnly is what the caller asked for; readOnly is what the open achieved
+	wantReadOnly bool
+
+	// target is the device path to open when several mice are connected; empty
+	// opens the first one found
+	target string
+
+	// supportedRates caches a complete SupportedRates probe; nil means not probed yet
+	supportedRates map[int]bool
+
+	// writeRate sends a rate command; it's writePollingRate unless swapped out in tests
+	writeRate func(rate int) error
+
+	// io serializes device access so only one command is in flight. Rate
+	// requests queued behind it are coalesced: only the latest one is written.
+	io        sync.Mutex
+	queueMu   sync.Mutex
+	queuedSeq uint64
+}
+
+// NewWindowsMouseController finds the LAMZU device at target (or the first
+// one, if empty) and opens it. Read-only controllers never request write
+// access, so they can coexist with software that holds the device for writing.
+func NewWindowsMouseController(target string, readOnly bool) (*WindowsMouseController, error) {
+	w := &WindowsMouseController{
+		handle:       syscall.InvalidHandle,
+		wantReadOnly: readOnly,
+		profile:      deviceProfile,
+		target:       target,
+	}
+	w.writeRate = w.writePollingRate
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// discoverDevices locates every LAMZU device by VID/PID/interface. It's a
+// variable so discovery can be swapped out, e.g. to simulate the path changing.
+var discoverDevices = findAllLAMZUDevices
+
+// findDevice picks the device to open: the target path when set, otherwise
+// the first device found. A target path that's gone is matched by VID/PID and
+// instance instead, as replugging can change the collection the path points at.
+func findDevice(target string) (string, HIDD_ATTRIBUTES, error) {
+	devices, err := discoverDevices()
+	if err != nil {
+		return "", HIDD_ATTRIBUTES{}, err
+	}
+	if target == "" {
+		return devices[0].path, devices[0].attributes, nil
+	}
+	for _, device := range devices {
+		if strings.EqualFold(device.path, target) {
+			return device.path, device.attributes, nil
+		}
+	}
+	for _, device := range devices {
+		if deviceKey(device.path) == deviceKey(target) {
+			return device.path, device.attributes, nil
+		}
+	}
+	return "", HIDD_ATTRIBUTES{}, fmt.Errorf("no LAMZU device at %s (%d other device(s) found, run with -v to list their paths)", target, len(devices))
+}
+
+// LAMZUDevicePaths lists the command interface path of every connected LAMZU device
+func LAMZUDevicePaths() ([]string, error) {
+	devices, err := discoverDevices()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(devices))
+	for i, device := range devices {
+		paths[i] = device.path
+	}
+	return paths, nil
 }
 
-func NewWindowsMouseController() (*WindowsMouseController, error) {
-	devicePath, attributes, err := findLAMZUDeviceWindows()
+// open resolves the device path afresh and opens it. Paths can change after a
+// replug or reboot, so the previous path is never reused.
+func (w *WindowsMouseController) open() error {
+	devicePath, attributes, err := findDevice(w.target)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find LAMZU device: %w", err)
+		return fmt.Errorf("failed to find LAMZU device: %w", err)
 	}
 
-	handle, err := openDeviceHandle(devicePath)
+	handle, writable, err := openDeviceWithFallback(devicePath, w.wantReadOnly)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open device: %w", err)
+		return fmt.Errorf("failed to open device: %w", err)
+	}
+
+	if w.devicePath != "" && w.devicePath != devicePath {
+		logDebugf("🔌 Device path changed: %s -> %s\n", w.devicePath, devicePath)
+		if deviceKey(w.devicePath) != deviceKey(devicePath) {
+			w.supportedRates = nil // A different mouse, probe it afresh
+		}
+	}
+	if w.target != "" {
+		w.target = devicePath // Follow the device to its new path on later reopens
+	}
+
+	w.handle = handle
+	w.devicePath = devicePath
+	w.attributes = attributes
+	w.readOnly = !writable
+	w.checkReportSize()
+	return nil
+}
+
+// checkReportSize compares the profile's report size with the feature report
+// length the device advertises. A profile without a size adopts the
+// advertised one; an explicit size that disagrees is kept but warned about.
+func (w *WindowsMouseController) checkReportSize() {
+	advertised, err := w.featureReportLength()
+	if err != nil {
+		logDebugf("⚠️ Could not read the device's report size (%v), using %d bytes\n", err, w.profile.reportSize())
+		return
+	}
+
+	switch {
+	case w.profile.ReportSize == 0 && advertised >= minReportSize:
+		w.profile.ReportSize = advertised
+	case w.profile.ReportSize != 0 && w.profile.ReportSize != advertised:
+		logWarnf("⚠️ device.report_size is %d but the device advertises %d-byte feature reports\n", w.profile.ReportSize, advertised)
+	}
+
+	logDebugf("📏 Feature report size: %d bytes (device advertises %d)\n", w.profile.reportSize(), advertised)
+}
+
+// featureReportLength reads the feature report length from the device's HID capabilities
+func (w *WindowsMouseController) featureReportLength() (int, error) {
+	caps, err := deviceCaps(w.handle)
+	if err != nil {
+		return 0, err
+	}
+	if caps.FeatureReportByteLength == 0 {
+		return 0, fmt.Errorf("device has no feature reports")
+	}
+	return int(caps.FeatureReportByteLength), nil
+}
+
+// deviceCaps reads the HID capabilities of an open handle. Any access level
+// works, including the zero-access handles used during discovery.
+func deviceCaps(handle syscall.Handle) (HIDP_CAPS, error) {
+	var preparsed uintptr
+	ret, _, err := hidD_GetPreparsedData.Call(uintptr(handle), uintptr(unsafe.Pointer(&preparsed)))
+	if ret == 0 {
+		return HIDP_CAPS{}, fmt.Errorf("HidD_GetPreparsedData failed: %v", err)
+	}
+	defer hidD_FreePreparsedData.Call(preparsed)
+
+	var caps HIDP_CAPS
+	status, _, _ := hidP_GetCaps.Call(preparsed, uintptr(unsafe.Pointer(&caps)))
+	if status != HIDP_STATUS_SUCCESS {
+		return HIDP_CAPS{}, fmt.Errorf("HidP_GetCaps failed: status 0x%08X", status)
+	}
+	return caps, nil
+}
+
+// acceptsCommands reports whether an interface has feature or output reports
+// long enough to carry the rate command
+func acceptsCommands(caps HIDP_CAPS) bool {
+	return int(caps.FeatureReportByteLength) >= minReportSize || int(caps.OutputReportByteLength) >= minReportSize
+}
+
+// Reopen closes the current handle and opens the device again after re-running discovery
+func (w *WindowsMouseController) Reopen() error {
+	w.io.Lock()
+	defer w.io.Unlock()
+
+	w.closeDevice()
+	return w.open()
+}
+
+// SetProfile switches the protocol profile on the open device; the next
+// command is built with it
+func (w *WindowsMouseController) SetProfile(profile DeviceProfile) {
+	w.io.Lock()
+	defer w.io.Unlock()
+
+	w.profile = profile
+	deviceProfile = profile
+	w.checkReportSize()
+}
+
+// setDeviceShareMode selects the share mode used for subsequent opens
+func setDeviceShareMode(mode string) error {
+	shareMode, ok := shareModes[strings.ToLower(mode)]
+	if !ok {
+		return fmt.Errorf("invalid share mode %q (valid: shared, read, exclusive)", mode)
 	}
+	deviceShareMode = shareMode
+	return nil
+}
 
-	return &WindowsMouseController{
-		handle:     handle,
-		devicePath: devicePath,
-		attributes: attributes,
-	}, nil
+// setDeviceProfile selects the protocol profile used for subsequent opens
+func setDeviceProfile(profile DeviceProfile) {
+	deviceProfile = profile
 }
 
 func (w *WindowsMouseController) Close() {
+	w.io.Lock()
+	defer w.io.Unlock()
+
+	w.closeDevice()
+}
+
+func (w *WindowsMouseController) closeDevice() {
 	if w.handle != syscall.InvalidHandle {
 		closeHandle.Call(uintptr(w.handle))
 		w.handle = syscall.InvalidHandle
@@ -99,55 +331,152 @@ func (w *WindowsMouseController) TestConnection() error {
 		return fmt.Errorf("device not connected")
 	}
 
-	if verbose {
-		fmt.Printf("🔌 Connected to LAMZU device via Windows API: VID=0x%04X, PID=0x%04X\n",
-			w.attributes.VendorID, w.attributes.ProductID)
-	}
+	logDebugf("🔌 Connected to LAMZU device via Windows API: VID=0x%04X, PID=0x%04X\n",
+		w.attributes.VendorID, w.attributes.ProductID)
 
 	return nil
 }
 
 func (w *WindowsMouseController) SetPollingRate(rate int) error {
-	rateValue, exists := pollingRateMap[rate]
-	if !exists {
+	if _, exists := pollingRateMap[rate]; !exists {
 		return fmt.Errorf("invalid polling rate: %d", rate)
 	}
 
-	// Use exact format from working TypeScript implementation
-	command := make([]byte, REPORT_SIZE)
-	command[0] = 0x00      // Report ID
-	command[1] = 0x00      // Padding (default fill)
-	command[2] = 0x00      // Padding (default fill)
-	command[3] = 0x02      // Command type
-	command[4] = 0x02      // Sub-command
-	command[5] = 0x01      // Parameter
-	command[6] = 0x00      // Reserved
-	command[7] = 1         // Configuration
-	command[8] = rateValue // Polling rate value
-
-	if verbose {
-		fmt.Printf("🔧 Sending command: [%02X %02X %02X %02X %02X %02X %02X %02X %02X...]\n",
-			command[0], command[1], command[2], command[3], command[4], command[5], command[6], command[7], command[8])
+	if capped := capPollingRate(rate); capped != rate {
+		logInfof("🔒 %dHz is above max_polling_rate, applying %dHz\n", rate, capped)
+		rate = capped
+	}
+
+	// Take a place in the queue before waiting for the device
+	w.queueMu.Lock()
+	w.queuedSeq++
+	seq := w.queuedSeq
+	w.queueMu.Unlock()
+
+	w.io.Lock()
+	defer w.io.Unlock()
+
+	w.queueMu.Lock()
+	superseded := seq != w.queuedSeq
+	w.queueMu.Unlock()
+	if superseded {
+		logDebugf("⏭️ Skipping %dHz, a newer rate request is waiting\n", rate)
+		return errSuperseded
+	}
+
+	// Rates a complete probe found unsupported are refused before writing
+	if w.supportedRates != nil && !w.supportedRates[rate] {
+		return fmt.Errorf("polling rate %dHz: %w", rate, errUnsupportedRate)
 	}
 
+	return w.writeRate(rate)
+}
+
+// SupportedRates probes which polling rates the device accepts by applying each
+// one and reading it back, then restores the original rate. Only a complete
+// probe is cached; one cut short by errors or max_polling_rate runs again next time.
+func (w *WindowsMouseController) SupportedRates() ([]int, error) {
+	w.io.Lock()
+	defer w.io.Unlock()
+
+	supported := w.supportedRates
+	if supported == nil {
+		if w.readOnly {
+			return nil, fmt.Errorf("cannot probe supported rates: device was opened read-only and probing writes every rate")
+		}
+		original, err := w.readPollingRate()
+		if err != nil {
+			return nil, fmt.Errorf("cannot probe supported rates without reading the current rate: %w", err)
+		}
+
+		supported = make(map[int]bool)
+		complete := true
+		var probeErr error
+		for _, rate := range sortedPollingRates() {
+			// Never probe past max_polling_rate, even briefly
+			if capPollingRate(rate) != rate {
+				complete = false
+				continue
+			}
+			if err := w.writePollingRate(rate); err != nil {
+				probeErr = fmt.Errorf("failed to apply %dHz: %w", rate, err)
+				break
+			}
+			actual, err := w.readPollingRate()
+			if err != nil {
+				probeErr = fmt.Errorf("failed to read back %dHz: %w", rate, err)
+				break
+			}
+			if actual == rate {
+				supported[rate] = true
+			} else {
+				logDebugf("⚠️ Device did not accept %dHz\n", rate)
+			}
+		}
+
+		if err := w.writePollingRate(original); err != nil {
+			return nil, fmt.Errorf("failed to restore %dHz after probing: %w", original, err)
+		}
+		if probeErr != nil {
+			return nil, fmt.Errorf("probing supported rates failed: %w", probeErr)
+		}
+		if complete {
+			w.supportedRates = supported
+		}
+	}
+
+	var rates []int
+	for _, rate := range sortedPollingRates() {
+		if supported[rate] {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+// writePollingRate sends the polling rate command without capability checks
+func (w *WindowsMouseController) writePollingRate(rate int) error {
+	rateValue := pollingRateMap[rate]
+
+	if w.readOnly {
+		return fmt.Errorf("device was opened read-only because another program holds it for writing - close the official LAMZU software and try again")
+	}
+
+	slot, err := w.commandSlot()
+	if err != nil {
+		return err
+	}
+
+	// Use exact format from working TypeScript implementation
+	command := make([]byte, w.profile.reportSize())
+	command[0] = w.profile.ReportID // Report ID
+	command[1] = 0x00               // Padding (default fill)
+	command[2] = 0x00               // Padding (default fill)
+	command[3] = 0x02               // Command type
+	command[4] = 0x02               // Sub-command
+	command[5] = 0x01               // Parameter
+	command[6] = 0x00               // Reserved
+	command[7] = slot               // Configuration (onboard slot)
+	command[8] = rateValue          // Polling rate value
+
+	logDebugf("🔧 Sending command: [%02X %02X %02X %02X %02X %02X %02X %02X %02X...]\n",
+		command[0], command[1], command[2], command[3], command[4], command[5], command[6], command[7], command[8])
+
 	// Try HidD_SetFeature first (for feature reports)
 	ret, _, err := hidD_SetFeature.Call(
 		uintptr(w.handle),
 		uintptr(unsafe.Pointer(&command[0])),
 		uintptr(len(command)),
 	)
+	logHID("HidD_SetFeature", "→", command, ret, err)
 
 	if ret != 0 {
-		if verbose {
-			fmt.Printf("📡 Polling rate set to %dHz (value: %d) via HidD_SetFeature\n", rate, rateValue)
-		}
-		return nil
+		logDebugf("📡 Polling rate set to %dHz (value: %d) via HidD_SetFeature\n", rate, rateValue)
+		return w.checkAck()
 	}
 
 	// If HidD_SetFeature fails, try WriteFile (for output reports)
-	if verbose {
-		fmt.Printf("⚠️ HidD_SetFeature failed (%v), trying WriteFile...\n", err)
-	}
+	logDebugf("⚠️ HidD_SetFeature failed (%v), trying WriteFile...\n", err)
 
 	var bytesWritten uint32
 	ret, _, err = writeFile.Call(
@@ -157,33 +486,222 @@ func (w *WindowsMouseController) SetPollingRate(rate int) error {
 		uintptr(unsafe.Pointer(&bytesWritten)),
 		0,
 	)
+	logHID("WriteFile", "→", command, ret, err)
 
 	if ret == 0 {
 		return fmt.Errorf("failed to write command (both HidD_SetFeature and WriteFile failed): %v", err)
 	}
 
-	if verbose {
-		fmt.Printf("📡 Polling rate set to %dHz (value: %d) via WriteFile (%d bytes written)\n", rate, rateValue, bytesWritten)
+	logDebugf("📡 Polling rate set to %dHz (value: %d) via WriteFile (%d bytes written)\n", rate, rateValue, bytesWritten)
+
+	return w.checkAck()
+}
+
+// commandSlot resolves the onboard slot to write to. With "auto" the slot is
+// read back from the device's current report, falling back to the default
+// slot if it doesn't report one.
+func (w *WindowsMouseController) commandSlot() (byte, error) {
+	if w.profile.Slot != slotAuto {
+		return parseSlot(w.profile.Slot)
+	}
+
+	report, err := w.readFeatureReport()
+	if err != nil {
+		return 0, fmt.Errorf("failed to detect active slot: %w", err)
 	}
 
+	slot := report[7]
+	if slot == 0 {
+		slot = defaultSlot
+	}
+	logDebugf("🗃️ Active onboard slot: %d\n", slot)
+	return slot, nil
+}
+
+// logHID dumps a full report and the call's result when --verbose-hid is set
+func logHID(call, direction string, data []byte, ret uintptr, err error) {
+	if !verboseHID {
+		return
+	}
+
+	logDebugf("🔬 %s %s ret=%d err=%v (%d bytes)\n", call, direction, ret, err, len(data))
+	for offset := 0; offset < len(data); offset += 16 {
+		end := min(offset+16, len(data))
+		logDebugf("   %02X: % X\n", offset, data[offset:end])
+	}
+}
+
+// checkAck reads the response report after a command and fails if the
+// device's status byte reports a rejection. Profiles without an ack skip this.
+func (w *WindowsMouseController) checkAck() error {
+	ack := w.profile.Ack
+	if ack == nil {
+		return nil
+	}
+
+	if ack.StatusOffset < 1 || ack.StatusOffset >= w.profile.reportSize() {
+		return fmt.Errorf("invalid ack status offset %d", ack.StatusOffset)
+	}
+
+	report, err := w.readFeatureReport()
+	if err != nil {
+		return fmt.Errorf("failed to read command acknowledgment: %w", err)
+	}
+
+	status := report[ack.StatusOffset]
+	if status != ack.OK {
+		return fmt.Errorf("device rejected the command (status 0x%02X, expected 0x%02X)", status, ack.OK)
+	}
+
+	logDebugf("✅ Device acknowledged the command (status 0x%02X)\n", status)
 	return nil
 }
 
+func (w *WindowsMouseController) GetPollingRate() (int, error) {
+	w.io.Lock()
+	defer w.io.Unlock()
+
+	return w.readPollingRate()
+}
+
+// readPollingRate reads the current rate; callers hold w.io
+func (w *WindowsMouseController) readPollingRate() (int, error) {
+	if w.handle == syscall.InvalidHandle {
+		return 0, fmt.Errorf("device not connected")
+	}
+
+	var report []byte
+	var err error
+	switch w.profile.ReadMethod {
+	case readMethodFeature:
+		report, err = w.readFeatureReport()
+	case readMethodInput:
+		report, err = w.readInputReport(inputReportTimeout)
+	default:
+		report, err = w.readFeatureReport()
+		if err != nil {
+			logDebugf("⚠️ %v, trying input report...\n", err)
+			var inputErr error
+			report, inputErr = w.readInputReport(inputReportTimeout)
+			if inputErr != nil {
+				return 0, fmt.Errorf("device doesn't report its polling rate (%v; %v)", err, inputErr)
+			}
+			err = nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	rateValue := report[8]
+	rate, ok := pollingRateFromByte(rateValue)
+	if !ok {
+		return 0, fmt.Errorf("device reported unknown polling rate value: 0x%02X", rateValue)
+	}
+
+	logDebugf("📡 Device reports polling rate %dHz (value: %d)\n", rate, rateValue)
+
+	return rate, nil
+}
+
+// inputReportTimeout bounds how long to wait for the device to send an input report
+const inputReportTimeout = 500 * time.Millisecond
+
+// readFeatureReport fetches the rate report via HidD_GetFeature. The device
+// answers with the same layout used by SetPollingRate.
+func (w *WindowsMouseController) readFeatureReport() ([]byte, error) {
+	report := make([]byte, w.profile.reportSize())
+	report[0] = w.profile.ReportID // Report ID
+
+	ret, _, err := hidD_GetFeature.Call(
+		uintptr(w.handle),
+		uintptr(unsafe.Pointer(&report[0])),
+		uintptr(len(report)),
+	)
+	logHID("HidD_GetFeature", "←", report, ret, err)
+	if ret == 0 {
+		return nil, fmt.Errorf("failed to read feature report: %v", err)
+	}
+
+	return report, nil
+}
+
+// readInputReport waits for an input report with the profile's report ID, for
+// firmwares that don't answer GetFeature. The handle is synchronous, so a
+// blocked ReadFile is cancelled with CancelIoEx once the timeout passes.
+func (w *WindowsMouseController) readInputReport(timeout time.Duration) ([]byte, error) {
+	type result struct {
+		report []byte
+		err    error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		report := make([]byte, w.profile.reportSize())
+		var bytesRead uint32
+		ret, _, err := readFile.Call(
+			uintptr(w.handle),
+			uintptr(unsafe.Pointer(&report[0])),
+			uintptr(len(report)),
+			uintptr(unsafe.Pointer(&bytesRead)),
+			0,
+		)
+		logHID("ReadFile", "←", report[:bytesRead], ret, err)
+		if ret == 0 {
+			done <- result{err: fmt.Errorf("failed to read input report: %v", err)}
+			return
+		}
+		if bytesRead < minReportSize || report[0] != w.profile.ReportID {
+			done <- result{err: fmt.Errorf("unexpected input report (%d bytes, report ID 0x%02X)", bytesRead, report[0])}
+			return
+		}
+		done <- result{report: report}
+	}()
+
+	select {
+	case r := <-done:
+		return r.report, r.err
+	case <-time.After(timeout):
+		cancelIoEx.Call(uintptr(w.handle), 0)
+		<-done
+		return nil, fmt.Errorf("timed out after %s waiting for an input report", timeout)
+	}
+}
+
 func (w *WindowsMouseController) GetDeviceInfo() (*HIDD_ATTRIBUTES, error) {
 	return &w.attributes, nil
 }
 
-func findLAMZUDeviceWindows() (string, HIDD_ATTRIBUTES, error) {
+func (w *WindowsMouseController) DeviceInfo() DeviceInfo {
+	info := DeviceInfo{
+		Path:      w.devicePath,
+		VendorID:  w.attributes.VendorID,
+		ProductID: w.attributes.ProductID,
+		Version:   w.attributes.VersionNumber,
+	}
+	if caps, err := deviceCaps(w.handle); err == nil {
+		info.Caps = &DeviceCaps{
+			UsagePage:     caps.UsagePage,
+			Usage:         caps.Usage,
+			InputLength:   int(caps.InputReportByteLength),
+			OutputLength:  int(caps.OutputReportByteLength),
+			FeatureLength: int(caps.FeatureReportByteLength),
+		}
+	}
+	return info
+}
+
+// findAllLAMZUDevices enumerates the HID interfaces of every connected LAMZU
+// device and picks the one each device takes commands on
+func findAllLAMZUDevices() ([]discoveredInterface, error) {
 	var hidGuid GUID
 
 	hidD_GetHidGuid.Call(uintptr(unsafe.Pointer(&hidGuid)))
 
-	if verbose {
-		fmt.Printf("🔍 HID GUID: {%08X-%04X-%04X-%02X%02X-%02X%02X%02X%02X%02X%02X}\n",
-			hidGuid.Data1, hidGuid.Data2, hidGuid.Data3,
-			hidGuid.Data4[0], hidGuid.Data4[1], hidGuid.Data4[2], hidGuid.Data4[3],
-			hidGuid.Data4[4], hidGuid.Data4[5], hidGuid.Data4[6], hidGuid.Data4[7])
-	}
+	logDebugf("🔍 HID GUID: {%08X-%04X-%04X-%02X%02X-%02X%02X%02X%02X%02X%02X}\n",
+		hidGuid.Data1, hidGuid.Data2, hidGuid.Data3,
+		hidGuid.Data4[0], hidGuid.Data4[1], hidGuid.Data4[2], hidGuid.Data4[3],
+		hidGuid.Data4[4], hidGuid.Data4[5], hidGuid.Data4[6], hidGuid.Data4[7])
 
 	hDevInfo, _, _ := setupDiGetClassDevs.Call(
 		uintptr(unsafe.Pointer(&hidGuid)),
@@ -193,14 +711,20 @@ func findLAMZUDeviceWindows() (string, HIDD_ATTRIBUTES, error) {
 	)
 
 	if hDevInfo == INVALID_HANDLE_VALUE {
-		return "", HIDD_ATTRIBUTES{}, errors.New("failed to get device list")
+		return nil, errors.New("failed to get device list")
 	}
 	defer setupDiDestroyDeviceInfoList.Call(hDevInfo)
 
 	var deviceIndex uint32 = 0
-	var deviceFound bool
-	var foundPath string
-	var foundAttributes HIDD_ATTRIBUTES
+	var seenInterfaces []int
+	vendorID, productIDs := deviceProfile.deviceIDs()
+
+	// Interfaces are grouped per physical device, in the order devices were found
+	type deviceCandidates struct {
+		preferred, fallback []discoveredInterface
+	}
+	candidates := make(map[string]*deviceCandidates)
+	var order []string
 
 	for {
 		var deviceInterfaceData SP_DEVICE_INTERFACE_DATA
@@ -248,11 +772,10 @@ func findLAMZUDeviceWindows() (string, HIDD_ATTRIBUTES, error) {
 
 		devicePath := syscall.UTF16ToString((*[256]uint16)(unsafe.Pointer(&detail.DevicePath[0]))[:256])
 
-		if verbose {
-			fmt.Printf("🔍 Checking device: %s\n", devicePath)
-		}
+		logDebugf("🔍 Checking device: %s\n", devicePath)
 
-		handle, err := openDeviceHandle(devicePath)
+		// Attribute queries need no access rights, so busy devices are still identified
+		handle, err := openDeviceHandle(devicePath, 0)
 		if err != nil {
 			deviceIndex++
 			continue
@@ -266,32 +789,41 @@ func findLAMZUDeviceWindows() (string, HIDD_ATTRIBUTES, error) {
 			uintptr(unsafe.Pointer(&attributes)),
 		)
 
+		caps, capsErr := deviceCaps(handle)
 		closeHandle.Call(uintptr(handle))
 
 		if ret != 0 {
-			if verbose {
-				fmt.Printf("📊 Device VID=0x%04X, PID=0x%04X\n", attributes.VendorID, attributes.ProductID)
-			}
+			logDebugf("📊 Device VID=0x%04X, PID=0x%04X\n", attributes.VendorID, attributes.ProductID)
 
-			if attributes.VendorID == LAMZU_VID && attributes.ProductID == LAMZU_PID {
+			if attributes.VendorID == vendorID && slices.Contains(productIDs, attributes.ProductID) {
 				// Extract interface number from device path (mi_XX)
 				interfaceNum := extractInterfaceNumber(devicePath)
 
-				if verbose {
-					fmt.Printf("🔍 Found LAMZU device interface %d: %s\n", interfaceNum, devicePath)
+				logDebugf("🔍 Found LAMZU device interface %d: %s\n", interfaceNum, devicePath)
+				seenInterfaces = append(seenInterfaces, interfaceNum)
+
+				candidate := discoveredInterface{path: devicePath, attributes: attributes, caps: caps, capsErr: capsErr}
+				if capsErr != nil {
+					logDebugf("   Capabilities unknown: %v\n", capsErr)
+				} else {
+					logDebugf("   Usage page 0x%04X, feature %d bytes, output %d bytes\n", caps.UsagePage, caps.FeatureReportByteLength, caps.OutputReportByteLength)
 				}
 
-				// Only use interface 2 (same as karalabe/hid implementation)
-				if interfaceNum == INTERFACE_NUMBER {
-					if verbose {
-						fmt.Printf("✅ Found LAMZU device on correct interface %d: %s\n", interfaceNum, devicePath)
-					}
-					deviceFound = true
-					foundPath = devicePath
-					foundAttributes = attributes
-					break
-				} else if verbose {
-					fmt.Printf("⚠️ Skipping LAMZU device on interface %d (need interface %d)\n", interfaceNum, INTERFACE_NUMBER)
+				key := deviceKey(devicePath)
+				device, ok := candidates[key]
+				if !ok {
+					device = &deviceCandidates{}
+					candidates[key] = device
+					order = append(order, key)
+				}
+
+				// Prefer interface 2 (same as karalabe/hid implementation) or the
+				// --interface override; others are only used when it can't take
+				// commands, or with --all-interfaces
+				if interfaceNum == hidInterface {
+					device.preferred = append(device.preferred, candidate)
+				} else if (capsErr == nil && acceptsCommands(caps)) || (anyInterface && capsErr != nil) {
+					device.fallback = append(device.fallback, candidate)
 				}
 			}
 		}
@@ -299,11 +831,126 @@ func findLAMZUDeviceWindows() (string, HIDD_ATTRIBUTES, error) {
 		deviceIndex++
 	}
 
-	if !deviceFound {
-		return "", HIDD_ATTRIBUTES{}, fmt.Errorf("LAMZU device not found - make sure it's connected and you're running as administrator")
+	// One device per preferred interface that takes commands
+	var devices []discoveredInterface
+	var preferred, fallback []discoveredInterface
+	for _, key := range order {
+		device := candidates[key]
+		preferred = append(preferred, device.preferred...)
+		fallback = append(fallback, device.fallback...)
+		if len(device.preferred) == 0 {
+			continue
+		}
+		if candidate, err := pickInterface(device.preferred, nil); err == nil {
+			devices = append(devices, candidate)
+		}
+	}
+
+	// Other interfaces of a mouse can carry a different instance ID, so they
+	// can't be matched to a device; use one only when nothing else works
+	if len(devices) == 0 {
+		if candidate, err := pickInterface(preferred, fallback); err == nil {
+			devices = append(devices, candidate)
+		}
+	}
+
+	if len(devices) > 0 {
+		if len(devices) > 1 {
+			logDebugf("🖱️ Found %d LAMZU devices:\n", len(devices))
+			for _, device := range devices {
+				logDebugf("   %s\n", device.path)
+			}
+		}
+		return devices, nil
+	}
+
+	if len(preferred) > 0 {
+		return nil, fmt.Errorf("LAMZU device found, but no interface supports feature or output reports of at least %d bytes", minReportSize)
+	}
+	if len(seenInterfaces) > 0 {
+		return nil, fmt.Errorf("LAMZU device found, but not on interface %d (found interfaces %s) - try --interface <n> or --all-interfaces", hidInterface, interfaceList(seenInterfaces))
+	}
+	return nil, fmt.Errorf("LAMZU device not found (VID 0x%04X, PID %s) - make sure it's connected and you're running as administrator", vendorID, productIDList(productIDs))
+}
+
+// pickInterface chooses the interface of one device to send commands to:
+// the preferred interface if it accepts them, otherwise the first fallback.
+// An interface whose capabilities can't be read is trusted, as before.
+func pickInterface(preferred, fallback []discoveredInterface) (discoveredInterface, error) {
+	for _, candidate := range preferred {
+		if candidate.capsErr != nil || acceptsCommands(candidate.caps) {
+			logDebugf("✅ Found LAMZU device on correct interface %d: %s\n", hidInterface, candidate.path)
+			return candidate, nil
+		}
+		logDebugf("⚠️ Interface %d collection has no feature/output reports: %s\n", hidInterface, candidate.path)
+	}
+	if len(fallback) > 0 {
+		candidate := fallback[0]
+		if len(preferred) > 0 {
+			logWarnf("⚠️ Interface %d doesn't accept commands, using interface %d instead\n", hidInterface, extractInterfaceNumber(candidate.path))
+		} else {
+			logWarnf("⚠️ LAMZU device has no interface %d, using interface %d instead\n", hidInterface, extractInterfaceNumber(candidate.path))
+		}
+		return candidate, nil
+	}
+	return discoveredInterface{}, fmt.Errorf("no usable interface")
+}
+
+// deviceKey groups the collections of one interface, so several mice can be
+// told apart. Paths look like
+// \\?\hid#vid_373e&pid_001e&mi_02&col01#8&1d2e3f4&0&0000#{guid}: the
+// collection and trailing instance number vary within one interface.
+func deviceKey(devicePath string) string {
+	parts := strings.Split(strings.ToLower(devicePath), "#")
+	if len(parts) < 3 {
+		return strings.ToLower(devicePath)
+	}
+
+	hardwareID := parts[1]
+	if i := strings.Index(hardwareID, "&mi_"); i != -1 {
+		hardwareID = hardwareID[:i]
+	}
+	instance := parts[2]
+	fields := strings.Split(instance, "&")
+	if len(fields) > 1 {
+		instance = strings.Join(fields[:len(fields)-1], "&")
 	}
+	return hardwareID + "#" + instance
+}
 
-	return foundPath, foundAttributes, nil
+// productIDList formats product IDs for error messages
+func productIDList(productIDs []uint16) string {
+	names := make([]string, len(productIDs))
+	for i, id := range productIDs {
+		names[i] = fmt.Sprintf("0x%04X", id)
+	}
+	return strings.Join(names, "/")
+}
+
+// interfaceList formats interface numbers for error messages, once each
+func interfaceList(interfaces []int) string {
+	seen := make(map[int]bool)
+	var names []string
+	for _, n := range interfaces {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		if n < 0 {
+			names = append(names, "none")
+		} else {
+			names = append(names, strconv.Itoa(n))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// discoveredInterface is a LAMZU HID interface seen during discovery
+type discoveredInterface struct {
+	path       string
+	attributes HIDD_ATTRIBUTES
+	caps       HIDP_CAPS
+	capsErr    error
 }
 
 func extractInterfaceNumber(devicePath string) int {
@@ -333,16 +980,45 @@ func extractInterfaceNumber(devicePath string) int {
 	return -1
 }
 
-func openDeviceHandle(devicePath string) (syscall.Handle, error) {
+// openDeviceWithFallback opens the device with the most access available, stepping
+// down from read/write to write-only to read-only. It reports whether the handle can write.
+func openDeviceWithFallback(devicePath string, readOnly bool) (syscall.Handle, bool, error) {
+	if !readOnly {
+		if handle, err := openDeviceHandle(devicePath, GENERIC_READ|GENERIC_WRITE); err == nil {
+			return handle, true, nil
+		} else {
+			logDebugf("⚠️ Read/write open failed (%v), retrying with reduced access...\n", err)
+		}
+
+		if handle, err := openDeviceHandle(devicePath, GENERIC_WRITE); err == nil {
+			return handle, true, nil
+		} else {
+			logDebugf("⚠️ Write-only open failed (%v), retrying read-only...\n", err)
+		}
+	}
+
+	handle, err := openDeviceHandle(devicePath, GENERIC_READ)
+	if err != nil {
+		return syscall.InvalidHandle, false, fmt.Errorf("%w - the device may be held exclusively by the official LAMZU software, close it and try again", err)
+	}
+
+	if !readOnly {
+		logWarnf("⚠️ Device opened read-only, polling rate changes will fail until the official LAMZU software is closed\n")
+	}
+
+	return handle, false, nil
+}
+
+func openDeviceHandle(devicePath string, access uint32) (syscall.Handle, error) {
 	pathPtr, err := syscall.UTF16PtrFromString(devicePath)
 	if err != nil {
 		return syscall.InvalidHandle, err
 	}
 
-	handle, _, _ := createFile.Call(
+	handle, _, callErr := createFile.Call(
 		uintptr(unsafe.Pointer(pathPtr)),
-		GENERIC_READ|GENERIC_WRITE,
-		FILE_SHARE_READ|FILE_SHARE_WRITE,
+		uintptr(access),
+		uintptr(deviceShareMode),
 		0,
 		OPEN_EXISTING,
 		0,
@@ -350,7 +1026,7 @@ func openDeviceHandle(devicePath string) (syscall.Handle, error) {
 	)
 
 	if handle == INVALID_HANDLE_VALUE {
-		return syscall.InvalidHandle, errors.New("failed to open device")
+		return syscall.InvalidHandle, fmt.Errorf("failed to open device: %v", callErr)
 	}
 
 	return syscall.Handle(handle), nil
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestConcurrentSetPollingRate issues overlapping sets from many goroutines
+// (run with -race): writes must never overlap, and every call either wrote
+// its rate or was superseded by a newer request
+func TestConcurrentSetPollingRate(t *testing.T) {
+	var inFlight, overlaps atomic.Int32
+	var mu sync.Mutex
+	var written []int
+
+	w := &WindowsMouseController{handle: syscall.InvalidHandle, profile: defaultDeviceProfile}
+	w.writeRate = func(rate int) error {
+		if inFlight.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer inFlight.Add(-1)
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		written = append(written, rate)
+		mu.Unlock()
+		return nil
+	}
+
+	rates := []int{125, 250, 500, 1000, 2000, 4000, 8000}
+	var applied, superseded atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(rate int) {
+			defer wg.Done()
+			switch err := w.SetPollingRate(rate); {
+			case err == nil:
+				applied.Add(1)
+			case errors.Is(err, errSuperseded):
+				superseded.Add(1)
+			default:
+				t.Errorf("SetPollingRate(%d): %v", rate, err)
+			}
+		}(rates[i%len(rates)])
+	}
+	wg.Wait()
+
+	if n := overlaps.Load(); n > 0 {
+		t.Errorf("%d writes overlapped another write", n)
+	}
+	if int(applied.Load()) != len(written) {
+		t.Errorf("%d calls reported success but %d rates were written", applied.Load(), len(written))
+	}
+	if applied.Load()+superseded.Load() != 50 {
+		t.Errorf("applied %d + superseded %d, want 50 calls", applied.Load(), superseded.Load())
+	}
+	if len(written) == 0 {
+		t.Error("no rate was written")
+	}
+}
+
+// Command interface paths of one mouse before and after a replug: the
+// collection and the last instance field change, deviceKey doesn't
+const (
+	pathBeforeReplug = `\\?\hid#vid_373e&pid_001e&mi_02&col01#7&1a2b3c4d&0&0001#{4d1e55b2-f16f-11cf-88cb-001111000030}`
+	pathAfterReplug  = `\\?\hid#vid_373e&pid_001e&mi_02&col02#7&1a2b3c4d&0&0000#{4d1e55b2-f16f-11cf-88cb-001111000030}`
+	pathSecondMouse  = `\\?\hid#vid_373e&pid_001e&mi_02&col01#7&5e6f7a8b&0&0001#{4d1e55b2-f16f-11cf-88cb-001111000030}`
+)
+
+// fakeDiscovery swaps discoverDevices for a list of paths the test controls
+func fakeDiscovery(t *testing.T, paths *[]string) {
+	t.Helper()
+	previous := discoverDevices
+	discoverDevices = func() ([]discoveredInterface, error) {
+		if len(*paths) == 0 {
+			return nil, errors.New("no LAMZU device found")
+		}
+		devices := make([]discoveredInterface, len(*paths))
+		for i, path := range *paths {
+			devices[i] = discoveredInterface{path: path}
+		}
+		return devices, nil
+	}
+	t.Cleanup(func() { discoverDevices = previous })
+}
+
+// discoveredMouse is a fake mouse at a device path that only reopens while
+// discovery still lists that path
+type discoveredMouse struct {
+	*fakeMouse
+	path  string
+	paths *[]string
+}
+
+func (m *discoveredMouse) DeviceInfo() DeviceInfo { return DeviceInfo{Path: m.path} }
+
+func (m *discoveredMouse) Reopen() error {
+	if !slices.Contains(*m.paths, m.path) {
+		return fmt.Errorf("device %s is gone", m.path)
+	}
+	return m.fakeMouse.Reopen()
+}
+
+// TestFindDeviceFollowsReplug checks a target path that disappeared is matched
+// to the same mouse at its new path, and not to another mouse
+func TestFindDeviceFollowsReplug(t *testing.T) {
+	paths := []string{pathSecondMouse, pathAfterReplug}
+	fakeDiscovery(t, &paths)
+
+	path, _, err := findDevice(pathBeforeReplug)
+	if err != nil {
+		t.Fatalf("findDevice: %v", err)
+	}
+	if path != pathAfterReplug {
+		t.Errorf("findDevice picked %s, want %s", path, pathAfterReplug)
+	}
+
+	paths = []string{pathSecondMouse}
+	if path, _, err := findDevice(pathBeforeReplug); err == nil {
+		t.Errorf("findDevice picked %s for an unplugged mouse, want an error", path)
+	}
+}
+
+// TestMultiMouseReconnect unplugs the only mouse, checks it's dropped, then
+// plugs it back in at a new path and checks the next rate change reaches it
+func TestMultiMouseReconnect(t *testing.T) {
+	paths := []string{pathBeforeReplug}
+	fakeDiscovery(t, &paths)
+
+	opened := make(map[string]*fakeMouse)
+	mice := &MultiMouseController{
+		discover: LAMZUDevicePaths,
+		open: func(path string) (MouseControllerInterface, error) {
+			mouse := &fakeMouse{rate: 1000}
+			opened[path] = mouse
+			return &discoveredMouse{fakeMouse: mouse, path: path, paths: &paths}, nil
+		},
+	}
+	mice.rescan()
+	if len(mice.current()) != 1 {
+		t.Fatalf("rescan opened %d mice, want 1", len(mice.current()))
+	}
+
+	paths = nil
+	if err := mice.Reopen(); !errors.Is(err, errNoMouse) {
+		t.Errorf("Reopen with the mouse unplugged = %v, want errNoMouse", err)
+	}
+	if err := mice.SetPollingRate(2000); !errors.Is(err, errNoMouse) {
+		t.Errorf("SetPollingRate with the mouse unplugged = %v, want errNoMouse", err)
+	}
+
+	paths = []string{pathAfterReplug}
+	if err := mice.SetPollingRate(2000); err != nil {
+		t.Fatalf("SetPollingRate after the replug: %v", err)
+	}
+	if mouse := opened[pathAfterReplug]; mouse == nil || mouse.currentRate() != 2000 {
+		t.Errorf("mouse at the new path wasn't opened and set to 2000")
+	}
+	if info := mice.DeviceInfo(); info.Path != pathAfterReplug {
+		t.Errorf("DeviceInfo().Path = %s, want %s", info.Path, pathAfterReplug)
+	}
+
+	// The replugged mouse is kept on reconnect, and a second one joins it
+	paths = []string{pathAfterReplug, pathSecondMouse}
+	if err := mice.Reopen(); err != nil {
+		t.Fatalf("Reopen with both mice connected: %v", err)
+	}
+	if len(mice.current()) != 2 {
+		t.Errorf("%d mice connected after the rescan, want 2", len(mice.current()))
+	}
+}
+
+// TestSetPollingRateRejectsUnsupportedRate checks a rate the probe found
+// unsupported is refused without a write, while supported rates still go through
+func TestSetPollingRateRejectsUnsupportedRate(t *testing.T) {
+	var written []int
+	w := &WindowsMouseController{handle: syscall.InvalidHandle, profile: defaultDeviceProfile}
+	w.writeRate = func(rate int) error {
+		written = append(written, rate)
+		return nil
+	}
+	w.supportedRates = map[int]bool{500: true, 1000: true, 2000: true}
+
+	if err := w.SetPollingRate(4000); !errors.Is(err, errUnsupportedRate) {
+		t.Errorf("SetPollingRate(4000) = %v, want errUnsupportedRate", err)
+	}
+	if err := w.SetPollingRate(2000); err != nil {
+		t.Errorf("SetPollingRate(2000): %v", err)
+	}
+	if !slices.Equal(written, []int{2000}) {
+		t.Errorf("wrote %v, want only 2000", written)
+	}
+
+	// Before a complete probe, every known rate is written
+	w.supportedRates = nil
+	if err := w.SetPollingRate(4000); err != nil {
+		t.Errorf("SetPollingRate(4000) without a probe: %v", err)
+	}
+}
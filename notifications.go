@@ -1,19 +1,43 @@
 package main
 
 import (
+	_ "embed"
 	"fmt"
 	"github.com/go-toast/toast"
+	"net/url"
+	"os"
 	"path/filepath"
+	"sync"
+	"time"
 )
 
+// Notification kinds, each with its own cooldown
+const (
+	notifyStarted  = "started"
+	notifyDetected = "detected"
+	notifyClosed   = "closed"
+	notifyError    = "error"
+	notifyState    = "state"
+)
+
+// embeddedIcon is used when no icon.png sits next to the app
+//
+//go:embed assets/icon.png
+var embeddedIcon []byte
+
 // NotificationManager handles Windows toast notifications
 type NotificationManager struct {
 	appID string
 	iconPath string
+
+	// cooldown is the minimum time between two notifications of the same kind
+	cooldown  time.Duration
+	mu        sync.Mutex
+	lastShown map[string]time.Time
 }
 
 // NewNotificationManager creates a new notification manager
-func NewNotificationManager() *NotificationManager {
+func NewNotificationManager(cooldown time.Duration) *NotificationManager {
 	// Get the executable path for icon
 	execPath, err := filepath.Abs(".")
 	iconPath := ""
@@ -21,12 +45,80 @@ func NewNotificationManager() *NotificationManager {
 		iconPath = filepath.Join(execPath, "icon.png")
 	}
 
+	// Fall back to the embedded icon when none is shipped alongside the binary
+	if _, err := os.Stat(iconPath); iconPath == "" || err != nil {
+		iconPath = extractEmbeddedIcon()
+	}
+
 	return &NotificationManager{
 		appID: "LAMZU.MouseAutomator",
 		iconPath: iconPath,
+		cooldown:  cooldown,
+		lastShown: make(map[string]time.Time),
+	}
+}
+
+// push shows the notification unless one of the same kind was shown within the cooldown
+func (nm *NotificationManager) push(kind string, notification toast.Notification) {
+	nm.mu.Lock()
+	if last, ok := nm.lastShown[kind]; ok && time.Since(last) < nm.cooldown {
+		nm.mu.Unlock()
+		logDebugf("🔕 Suppressed %s notification (cooldown %s)\n", kind, nm.cooldown)
+		return
+	}
+	nm.lastShown[kind] = time.Now()
+	nm.mu.Unlock()
+
+	err := notification.Push()
+	if err != nil && len(notification.Actions) > 0 {
+		// Older toast hosts may reject action buttons; the message alone still helps
+		notification.Actions = nil
+		err = notification.Push()
+	}
+	if err != nil && verbose {
+		logWarnf("⚠️ Failed to show notification: %v\n", err)
 	}
 }
 
+// configActions are toast buttons that open the config file and its folder, so
+// error notifications lead straight to the fix. None without a config file.
+func configActions() []toast.Action {
+	if noConfig {
+		return nil
+	}
+
+	path, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil
+	}
+
+	return []toast.Action{
+		{Type: "protocol", Label: "Abrir config", Arguments: fileURL(path)},
+		{Type: "protocol", Label: "Abrir pasta", Arguments: fileURL(filepath.Dir(path))},
+	}
+}
+
+// fileURL turns a Windows path into a file:// URL the shell can launch
+func fileURL(path string) string {
+	return (&url.URL{Scheme: "file", Path: "/" + filepath.ToSlash(path)}).String()
+}
+
+// extractEmbeddedIcon writes the embedded icon to the temp dir, since toast needs a file path
+func extractEmbeddedIcon() string {
+	iconPath := filepath.Join(os.TempDir(), "lamzu-automator-icon.png")
+
+	if stat, err := os.Stat(iconPath); err == nil && stat.Size() == int64(len(embeddedIcon)) {
+		return iconPath
+	}
+
+	if err := os.WriteFile(iconPath, embeddedIcon, 0644); err != nil {
+		logDebugf("⚠️ Failed to write notification icon: %v\n", err)
+		return ""
+	}
+
+	return iconPath
+}
+
 // ShowAppStarted shows notification when app starts
 func (nm *NotificationManager) ShowAppStarted() {
 	notification := toast.Notification{
@@ -36,23 +128,19 @@ func (nm *NotificationManager) ShowAppStarted() {
 		Icon:    nm.iconPath,
 	}
 
-	if err := notification.Push(); err != nil && verbose {
-		fmt.Printf("⚠️ Failed to show notification: %v\n", err)
-	}
+	nm.push(notifyStarted, notification)
 }
 
 // ShowGameDetected shows notification when a game is detected
-func (nm *NotificationManager) ShowGameDetected(pollingRate int) {
+func (nm *NotificationManager) ShowGameDetected(gameName string, pollingRate int) {
 	notification := toast.Notification{
 		AppID:   nm.appID,
 		Title:   "Jogo Detectado!",
-		Message: fmt.Sprintf("🎮 Alterando polling rate para %dHz", pollingRate),
+		Message: fmt.Sprintf("🎮 %s: alterando polling rate para %dHz", gameName, pollingRate),
 		Icon:    nm.iconPath,
 	}
 
-	if err := notification.Push(); err != nil && verbose {
-		fmt.Printf("⚠️ Failed to show notification: %v\n", err)
-	}
+	nm.push(notifyDetected, notification)
 }
 
 // ShowGameClosed shows notification when no game is running
@@ -64,9 +152,7 @@ func (nm *NotificationManager) ShowGameClosed(pollingRate int) {
 		Icon:    nm.iconPath,
 	}
 
-	if err := notification.Push(); err != nil && verbose {
-		fmt.Printf("⚠️ Failed to show notification: %v\n", err)
-	}
+	nm.push(notifyClosed, notification)
 }
 
 // ShowError shows error notification
@@ -76,9 +162,39 @@ func (nm *NotificationManager) ShowError(title, message string) {
 		Title:   title,
 		Message: fmt.Sprintf("❌ %s", message),
 		Icon:    nm.iconPath,
+		Actions: configActions(),
 	}
 
-	if err := notification.Push(); err != nil && verbose {
-		fmt.Printf("⚠️ Failed to show notification: %v\n", err)
+	nm.push(notifyError, notification)
+}
+
+// ShowUnresolvedGame warns that a scanned game without an executable seems to
+// be running, with buttons to open the config and add it
+func (nm *NotificationManager) ShowUnresolvedGame(gameName, executable string) {
+	notification := toast.Notification{
+		AppID:   nm.appID,
+		Title:   "Executável não configurado",
+		Message: fmt.Sprintf("⚠️ %s parece estar aberto (%s), mas não tem executável na config, então a polling rate não vai mudar", gameName, executable),
+		Icon:    nm.iconPath,
+		Actions: configActions(),
 	}
+
+	nm.push(notifyError, notification)
+}
+
+// ShowMonitoringState shows notification when monitoring is paused or resumed
+func (nm *NotificationManager) ShowMonitoringState(paused bool) {
+	message := "▶️ Monitoramento retomado"
+	if paused {
+		message = "⏸️ Monitoramento pausado"
+	}
+
+	notification := toast.Notification{
+		AppID:   nm.appID,
+		Title:   "LAMZU Automator",
+		Message: message,
+		Icon:    nm.iconPath,
+	}
+
+	nm.push(notifyState, notification)
 }
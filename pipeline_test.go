@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMouse stands in for the HID device and records every rate written to it
+type fakeMouse struct {
+	mu       sync.Mutex
+	rate     int
+	writes   []int
+	setErr   error
+	reopened int
+}
+
+func (m *fakeMouse) Close()                           {}
+func (m *fakeMouse) SetProfile(profile DeviceProfile) {}
+func (m *fakeMouse) TestConnection() error            { return nil }
+func (m *fakeMouse) DeviceInfo() DeviceInfo           { return DeviceInfo{Path: "fake"} }
+
+func (m *fakeMouse) Reopen() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reopened++
+	return nil
+}
+
+func (m *fakeMouse) SetPollingRate(rate int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.setErr != nil {
+		return m.setErr
+	}
+	m.rate = rate
+	m.writes = append(m.writes, rate)
+	return nil
+}
+
+func (m *fakeMouse) GetPollingRate() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rate, nil
+}
+
+func (m *fakeMouse) SupportedRates() ([]int, error) {
+	return []int{125, 250, 500, 1000, 2000, 4000, 8000}, nil
+}
+
+func (m *fakeMouse) currentRate() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rate
+}
+
+// writeTestFile creates a file and its parent folders
+func writeTestFile(t testing.TB, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeFakeGame adds an appmanifest and an install folder holding the game's executable
+func writeFakeGame(t testing.TB, libraryPath, appID, name, installDir, executable string) {
+	t.Helper()
+	manifest := fmt.Sprintf("\"AppState\"\n{\n\t\"appid\"\t\t\"%s\"\n\t\"name\"\t\t\"%s\"\n\t\"installdir\"\t\t\"%s\"\n\t\"SizeOnDisk\"\t\t\"1048576\"\n}\n", appID, name, installDir)
+	writeTestFile(t, filepath.Join(libraryPath, "steamapps", "appmanifest_"+appID+".acf"), manifest)
+	writeTestFile(t, filepath.Join(libraryPath, "steamapps", "common", installDir, executable), "MZ")
+}
+
+// vdfPath escapes a path the way Steam writes it in libraryfolders.vdf
+func vdfPath(path string) string {
+	return strings.ReplaceAll(path, `\`, `\\`)
+}
+
+// TestScanToWatchPipeline scans a fake Steam install with two libraries, saves
+// the result to a config, and checks the watcher switches rates for a detected game
+func TestScanToWatchPipeline(t *testing.T) {
+	root := t.TempDir()
+	steamPath := filepath.Join(root, "Steam")
+	libraryPath := filepath.Join(root, "SteamLibrary")
+
+	writeTestFile(t, filepath.Join(steamPath, "steam.exe"), "MZ")
+	writeTestFile(t, filepath.Join(steamPath, "steamapps", "libraryfolders.vdf"), fmt.Sprintf(
+		"\"libraryfolders\"\n{\n\t\"0\"\n\t{\n\t\t\"path\"\t\t\"%s\"\n\t\t\"label\"\t\t\"\"\n\t}\n\t\"1\"\n\t{\n\t\t\"path\"\t\t\"%s\"\n\t\t\"label\"\t\t\"Games\"\n\t}\n}\n",
+		vdfPath(steamPath), vdfPath(libraryPath)))
+	writeFakeGame(t, steamPath, "1145360", "Hades", "Hades", "Hades.exe")
+	writeFakeGame(t, libraryPath, "504230", "Celeste", "Celeste", "Celeste.exe")
+
+	configPath := filepath.Join(root, "config.yaml")
+	writeTestFile(t, configPath, "default_polling_rate: 1000\ngame_polling_rate: 2000\ncheck_interval: 2s\n")
+	previousConfigFile := configFile
+	configFile = configPath
+	t.Cleanup(func() { configFile = previousConfigFile })
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	config.Steam = &SteamConfig{InstallPath: steamPath}
+
+	detector := NewSteamDetector(config)
+	foundPath, err := detector.FindSteamInstallation()
+	if err != nil {
+		t.Fatalf("FindSteamInstallation: %v", err)
+	}
+	libraries, err := detector.DiscoverLibraries(foundPath)
+	if err != nil {
+		t.Fatalf("DiscoverLibraries: %v", err)
+	}
+	if len(libraries) != 2 {
+		t.Fatalf("got %d libraries, want 2: %+v", len(libraries), libraries)
+	}
+
+	games, err := NewGameScanner(libraries, 2).ScanAllLibraries()
+	if err != nil {
+		t.Fatalf("ScanAllLibraries: %v", err)
+	}
+	executables := make(map[string]bool)
+	for _, game := range games {
+		executables[game.Executable] = true
+	}
+	if !executables["Hades.exe"] || !executables["Celeste.exe"] {
+		t.Fatalf("scan resolved executables %v, want Hades.exe and Celeste.exe", executables)
+	}
+
+	changes, err := NewConfigUpdater(configPath).UpdateWithSteamData(foundPath, libraries, games, false, false)
+	if err != nil {
+		t.Fatalf("UpdateWithSteamData: %v", err)
+	}
+	if changes.Added != 2 {
+		t.Errorf("scan added %d games, want 2", changes.Added)
+	}
+
+	config, err = LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("reloading config: %v", err)
+	}
+	if len(config.DetectedGames) != 2 {
+		t.Fatalf("config has %d detected games, want 2", len(config.DetectedGames))
+	}
+
+	mouse := &fakeMouse{rate: config.DefaultPollingRate}
+	watcher := NewGameWatcher(config, mouse)
+	watcher.recordSightings = false
+	processes := []string{"explorer.exe", "Celeste.exe"}
+	watcher.processSource = func() ([]string, error) { return processes, nil }
+
+	watcher.checkProcesses()
+	if rate := mouse.currentRate(); rate != config.GamePollingRate {
+		t.Errorf("rate with Celeste running = %d, want %d", rate, config.GamePollingRate)
+	}
+	if running, rate := watcher.GetStatus(); !running || rate != config.GamePollingRate {
+		t.Errorf("GetStatus() = %v, %d; want true, %d", running, rate, config.GamePollingRate)
+	}
+
+	processes = []string{"explorer.exe"}
+	watcher.checkProcesses()
+	if rate := mouse.currentRate(); rate != config.DefaultPollingRate {
+		t.Errorf("rate after Celeste exited = %d, want %d", rate, config.DefaultPollingRate)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// processIDs returns the IDs of every process running the executable
+func processIDs(executable string) (map[uint32]bool, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot processes: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	target := strings.ToLower(normalizeProcessName(executable))
+	pids := make(map[uint32]bool)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if strings.ToLower(windows.UTF16ToString(entry.ExeFile[:])) == target {
+			pids[entry.ProcessID] = true
+		}
+	}
+
+	return pids, nil
+}
+
+// processImagePath returns the full path of the first running process for the executable
+func processImagePath(executable string) (string, error) {
+	pids, err := processIDs(executable)
+	if err != nil {
+		return "", err
+	}
+
+	for pid := range pids {
+		if path, err := imagePathForPID(pid); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not resolve path for %s", executable)
+}
+
+// imagePathForPID returns the full executable path of a process
+func imagePathForPID(pid uint32) (string, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", fmt.Errorf("failed to open process %d: %w", pid, err)
+	}
+	defer windows.CloseHandle(handle)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return "", fmt.Errorf("failed to query process %d path: %w", pid, err)
+	}
+
+	return windows.UTF16ToString(buf[:size]), nil
+}
+
+// processEntry is one row of a process snapshot
+type processEntry struct {
+	name   string
+	parent uint32
+}
+
+// processTree snapshots every running process keyed by PID
+func processTree() (map[uint32]processEntry, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot processes: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	tree := make(map[uint32]processEntry)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		tree[entry.ProcessID] = processEntry{
+			name:   windows.UTF16ToString(entry.ExeFile[:]),
+			parent: entry.ParentProcessID,
+		}
+	}
+
+	return tree, nil
+}
+
+// runsUnder reports whether a process matching executable has an ancestor
+// matching parent, using the config's matching rules for both names
+func runsUnder(config *Config, tree map[uint32]processEntry, executable, parent string) bool {
+	target := matchKey(config, executable)
+	ancestor := matchKey(config, parent)
+
+	for pid, entry := range tree {
+		if matchKey(config, entry.name) != target {
+			continue
+		}
+
+		// Walk up the tree; PIDs get reused, so cap the depth to avoid cycles
+		current := entry.parent
+		for depth := 0; depth < 32 && current != 0 && current != pid; depth++ {
+			up, ok := tree[current]
+			if !ok {
+				break
+			}
+			if matchKey(config, up.name) == ancestor {
+				return true
+			}
+			current = up.parent
+		}
+	}
+
+	return false
+}
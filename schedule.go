@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduleEntry sets the base (no game) polling rate for a daily time range
+type ScheduleEntry struct {
+	Start string `yaml:"start"` // "HH:MM", local time
+	End   string `yaml:"end"`   // "HH:MM", may be earlier than Start to cross midnight
+	Rate  int    `yaml:"rate"`
+}
+
+// scheduleTimeLayout is the clock format used by schedule entries
+const scheduleTimeLayout = "15:04"
+
+// parseScheduleClock converts "HH:MM" into minutes since midnight
+func parseScheduleClock(value string) (int, error) {
+	t, err := time.Parse(scheduleTimeLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains reports whether the entry's range covers the given time of day
+func (e ScheduleEntry) contains(now time.Time) bool {
+	start, err := parseScheduleClock(e.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseScheduleClock(e.End)
+	if err != nil {
+		return false
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// Range crosses midnight, e.g. 22:00-06:00
+	return minute >= start || minute < end
+}
+
+// scheduledRate returns the base rate for the given time: the first matching
+// schedule entry wins, falling back to DefaultPollingRate
+func scheduledRate(config *Config, now time.Time) int {
+	for _, entry := range config.Schedule {
+		if entry.contains(now) {
+			return entry.Rate
+		}
+	}
+	return config.DefaultPollingRate
+}
+
+// validateSchedule reports malformed schedule entries
+func validateSchedule(schedule []ScheduleEntry) []error {
+	var problems []error
+
+	for i, entry := range schedule {
+		if _, err := parseScheduleClock(entry.Start); err != nil {
+			problems = append(problems, fmt.Errorf("schedule[%d].start: %v", i, err))
+		}
+		if _, err := parseScheduleClock(entry.End); err != nil {
+			problems = append(problems, fmt.Errorf("schedule[%d].end: %v", i, err))
+		}
+		if _, ok := pollingRateMap[entry.Rate]; !ok {
+			problems = append(problems, fmt.Errorf("schedule[%d].rate: unsupported rate %d", i, entry.Rate))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Windows service registration
+const (
+	serviceName        = "LAMZUAutomator"
+	serviceDisplayName = "LAMZU Polling Rate Auto-Switch"
+	serviceDescription = "Switches the LAMZU mouse polling rate while games are running"
+
+	// serviceStopTimeout bounds how long uninstall waits for a running service to stop
+	serviceStopTimeout = 10 * time.Second
+)
+
+// connectServiceManager opens the service control manager, which needs an
+// elevated prompt for anything beyond querying
+func connectServiceManager() (*mgr.Mgr, error) {
+	m, err := mgr.Connect()
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return nil, fmt.Errorf("access denied - run this from an Administrator prompt")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	return m, nil
+}
+
+// installService registers this executable as an auto-start service that runs
+// the watcher with the current config file, and an event log source for it
+func installService() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+	// Services start in System32, so the config path must not be relative
+	config, err := filepath.Abs(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	m, err := connectServiceManager()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed (run service uninstall first to reinstall it)", serviceName)
+	}
+
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, "--config", config, "service", "run")
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer s.Close()
+
+	// Restart after a crash instead of leaving the mouse on whatever rate it had
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: 5 * time.Second}}, 24*60*60); err != nil {
+		logDebugf("⚠️ Failed to set service recovery actions: %v\n", err)
+	}
+
+	if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		s.Delete()
+		return fmt.Errorf("failed to register event log source: %w", err)
+	}
+	return nil
+}
+
+// uninstallService stops the service if it's running and removes it along
+// with its event log source
+func uninstallService() error {
+	m, err := connectServiceManager()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+
+	if status, err := s.Control(svc.Stop); err == nil {
+		deadline := time.Now().Add(serviceStopTimeout)
+		for status.State != svc.Stopped && time.Now().Before(deadline) {
+			time.Sleep(300 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	if err := eventlog.Remove(serviceName); err != nil {
+		logDebugf("⚠️ Failed to remove event log source: %v\n", err)
+	}
+	return nil
+}
+
+// runService hands control to the service control manager; it only works when
+// Windows started the process as the service
+func runService() error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return fmt.Errorf("failed to detect service context: %w", err)
+	}
+	if !isService {
+		return fmt.Errorf("service run is started by Windows; use service install to register the service")
+	}
+
+	events, err := eventlog.Open(serviceName)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer events.Close()
+	logEvents = events
+
+	return svc.Run(serviceName, &automatorService{})
+}
+
+// automatorService runs the same watcher loop as the foreground app. Services
+// live in session 0, so there are no toasts or hotkeys, and no user windows to
+// inspect: see applySessionZeroLimits.
+type automatorService struct{}
+
+// serviceStartWaitHint is how long each start-pending checkpoint tells the
+// service manager to wait; without updates it gives up on a service after 30s
+const serviceStartWaitHint = 10 * time.Second
+
+// applySessionZeroLimits adjusts the config for features that need the user's
+// desktop, which a service can't see. foreground detection would never find a
+// focused game, so it falls back to running; per-game resolution and refresh
+// rules can't measure the game's window and use the game's normal rate.
+func applySessionZeroLimits(config *Config) {
+	if config.DetectionMode == detectionForeground {
+		logWarnf("⚠️ detection_mode: foreground needs the user's desktop; the service watches running games instead\n")
+		config.DetectionMode = detectionRunning
+	}
+	for _, game := range configuredGames(config) {
+		if len(game.ResolutionRates) > 0 || len(game.RefreshRates) > 0 {
+			logWarnf("⚠️ %s has resolution or refresh rate rules, which the service can't apply (run lamzu-automator in the foreground for them)\n", game.Name)
+		}
+	}
+}
+
+// connectReportingProgress runs connectWhenReady while sending start-pending
+// checkpoints, since startup_delay plus startup_timeout can exceed the
+// service manager's start timeout
+func connectReportingProgress(config *Config, rate int, status chan<- svc.Status) (MouseControllerInterface, int, error) {
+	type result struct {
+		mouse    MouseControllerInterface
+		original int
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		mouse, original, err := connectWhenReady(config, rate)
+		done <- result{mouse, original, err}
+	}()
+
+	ticker := time.NewTicker(serviceStartWaitHint / 2)
+	defer ticker.Stop()
+	for checkpoint := uint32(1); ; checkpoint++ {
+		select {
+		case r := <-done:
+			return r.mouse, r.original, r.err
+		case <-ticker.C:
+			status <- svc.Status{State: svc.StartPending, CheckPoint: checkpoint, WaitHint: uint32(serviceStartWaitHint / time.Millisecond)}
+		}
+	}
+}
+
+func (s *automatorService) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending, WaitHint: uint32(serviceStartWaitHint / time.Millisecond)}
+
+	config, err := loadConfig()
+	if err != nil {
+		logErrorf("❌ Failed to load config: %v\n", err)
+		return true, 1
+	}
+	if problems := ValidateConfig(config); len(problems) > 0 {
+		logErrorf("❌ Config has %d problem(s), fix them before starting (see lamzu-automator validate): %v\n", len(problems), errors.Join(problems...))
+		return true, 1
+	}
+	applyActiveProfile(config)
+	applySessionZeroLimits(config)
+
+	mouse, originalRate, err := connectReportingProgress(config, scheduledRate(config, time.Now()), status)
+	if err != nil {
+		logErrorf("❌ %v\n", err)
+		return true, 2
+	}
+	defer mouse.Close()
+
+	watcher := NewGameWatcher(config, mouse)
+	go notifyEvents(watcher.Events(), nil)
+	metrics := serveMetrics(watcher)
+	watcher.Start()
+	logInfof("🚀 Service started, monitoring %d games\n", len(configuredGames(config)))
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for request := range requests {
+		if request.Cmd == svc.Interrogate {
+			status <- request.CurrentStatus
+			continue
+		}
+		if request.Cmd == svc.Stop || request.Cmd == svc.Shutdown {
+			break
+		}
+	}
+
+	status <- svc.Status{State: svc.StopPending}
+	metrics.Stop()
+	shutdown(watcher, mouse, config, originalRate)
+	logInfof("🛑 Service stopped\n")
+	return false, 0
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// autostartTaskName is the scheduled task created by setup and install.bat
+const autostartTaskName = "LAMZU Automator"
+
+// isInteractive reports whether stdin is a console someone can answer prompts
+// on. The GUI build and redirected input have no console, so setup is skipped.
+func isInteractive() bool {
+	return isConsole(os.Stdin)
+}
+
+// isConsole reports whether the file is attached to a console rather than a
+// pipe, file or nothing at all
+func isConsole(f *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
+
+// isFirstRun reports whether the config file hasn't been created yet
+func isFirstRun() bool {
+	if noConfig {
+		return false
+	}
+	_, err := os.Stat(configFile)
+	return os.IsNotExist(err)
+}
+
+// SetupWizard walks through detecting the mouse, choosing rates, scanning Steam
+// and enabling autostart, then writes the config
+type SetupWizard struct {
+	input *bufio.Reader
+}
+
+// NewSetupWizard creates a wizard reading answers from stdin
+func NewSetupWizard() *SetupWizard {
+	return &SetupWizard{input: bufio.NewReader(os.Stdin)}
+}
+
+// Run executes every step; existing settings are kept as the starting point
+func (sw *SetupWizard) Run() error {
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fmt.Println("🧙 LAMZU Automator Setup")
+	fmt.Println("========================")
+	fmt.Println("Press Enter to accept the value in [brackets].")
+
+	sw.detectMouse()
+
+	fmt.Println()
+	config.DefaultPollingRate = sw.chooseRate("Desktop polling rate", config.DefaultPollingRate)
+	config.GamePollingRate = sw.chooseRate("Game polling rate", config.GamePollingRate)
+
+	fmt.Println()
+	if sw.confirm("Scan Steam for installed games?", true) {
+		sw.scanSteam(config)
+	}
+
+	if problems := ValidateConfig(config); len(problems) > 0 {
+		return fmt.Errorf("setup produced an invalid config: %w", problems[0])
+	}
+	if err := NewConfigUpdater(configFile).saveConfigAtomic(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("\n💾 Config saved to %s\n", configFile)
+
+	fmt.Println()
+	if sw.confirm("Start automatically when you log in?", false) {
+		if err := enableAutostart(); err != nil {
+			fmt.Printf("❌ Failed to enable autostart: %v\n", err)
+			fmt.Println("💡 Try running setup as Administrator, or use install.bat")
+		} else {
+			fmt.Println("✅ Autostart enabled")
+		}
+	}
+
+	fmt.Println("\n🎉 Setup complete!")
+	return nil
+}
+
+// detectMouse checks the device can be found; setup continues either way
+func (sw *SetupWizard) detectMouse() {
+	fmt.Println("\n🔌 Looking for your LAMZU mouse...")
+	mouse, err := initMouseController(true)
+	if err != nil {
+		fmt.Printf("⚠️ Mouse not found (%v)\n", err)
+		fmt.Println("💡 Check it's connected and run as Administrator; you can finish setup anyway")
+		return
+	}
+	defer mouse.Close()
+
+	info := mouse.DeviceInfo()
+	fmt.Printf("✅ Found device VID 0x%04X / PID 0x%04X\n", info.VendorID, info.ProductID)
+	if rate, err := mouse.GetPollingRate(); err == nil {
+		fmt.Printf("📡 Current polling rate: %dHz\n", rate)
+	}
+}
+
+// chooseRate shows the supported rates as a numbered menu
+func (sw *SetupWizard) chooseRate(label string, current int) int {
+	rates := sortedPollingRates()
+	fmt.Printf("%s:\n", label)
+	for i, rate := range rates {
+		marker := ""
+		if rate == current {
+			marker = " (current)"
+		}
+		fmt.Printf("  %d) %dHz%s\n", i+1, rate, marker)
+	}
+
+	for {
+		answer := sw.ask(fmt.Sprintf("Choose 1-%d", len(rates)), strconv.Itoa(current)+"Hz")
+		if answer == "" {
+			return current
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(answer, "Hz")); err == nil {
+			if n >= 1 && n <= len(rates) {
+				return rates[n-1]
+			}
+			if _, ok := pollingRateMap[n]; ok {
+				return n
+			}
+		}
+		fmt.Println("❌ Invalid choice")
+	}
+}
+
+// scanSteam fills the Steam section and detected games in the config
+func (sw *SetupWizard) scanSteam(config *Config) {
+	detector := NewSteamDetector(config)
+	steamPath, err := detector.FindSteamInstallation()
+	if err != nil {
+		fmt.Printf("⚠️ Steam not found (%v), skipping scan\n", err)
+		return
+	}
+
+	libraries, err := detector.DiscoverLibraries(steamPath)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to discover Steam libraries: %v\n", err)
+		return
+	}
+
+	fmt.Println("🔍 Scanning Steam libraries...")
+	games, err := NewGameScanner(libraries, scanThreads).ScanAllLibraries()
+	if err != nil && verbose {
+		fmt.Printf("⚠️ Scan completed with warnings: %v\n", err)
+	}
+
+	if config.Steam == nil {
+		config.Steam = &SteamConfig{}
+	}
+	config.Steam.InstallPath = steamPath
+	config.Steam.Libraries = libraries
+	config.DetectedGames = games
+	fmt.Printf("🎮 Found %d games across %d libraries\n", len(games), len(libraries))
+}
+
+// enableAutostart registers a logon task that starts the automator in daemon mode
+func enableAutostart() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	output, err := exec.Command("schtasks", "/create", "/tn", autostartTaskName,
+		"/tr", fmt.Sprintf("\"%s\" -d", exe), "/sc", "onlogon", "/rl", "highest", "/f").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ask prompts for a line, showing the default in brackets
+func (sw *SetupWizard) ask(prompt, def string) string {
+	fmt.Printf("%s [%s]: ", prompt, def)
+	answer, _ := sw.input.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+// confirm asks a yes/no question
+func (sw *SetupWizard) confirm(prompt string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	switch strings.ToLower(sw.ask(prompt, hint)) {
+	case "":
+		return def
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/registry"
 )
 
+const (
+	libraryFoldersAttempts   = 4
+	libraryFoldersRetryDelay = 250 * time.Millisecond
+)
+
 // SteamDetector handles Steam installation detection
 type SteamDetector struct {
 	config *Config
@@ -26,8 +34,8 @@ func (sd *SteamDetector) FindSteamInstallation() (string, error) {
 	// Priority order as specified in CLAUDE.md:
 	// 1. Check config.yaml for saved path
 	// 2. Windows Registry: HKEY_CURRENT_USER\Software\Valve\Steam
-	// 3. Default locations: C:\Program Files (x86)\Steam
-	// 4. Environment variable: %STEAM_PATH%
+	// 3. Environment variable: %STEAM_PATH%
+	// 4. Common locations on every fixed drive (or steam.search_drives)
 
 	// 1. Check config.yaml for saved path
 	if sd.config.Steam != nil && sd.config.Steam.InstallPath != "" {
@@ -43,28 +51,88 @@ func (sd *SteamDetector) FindSteamInstallation() (string, error) {
 		}
 	}
 
-	// 3. Check default locations
-	defaultPaths := []string{
-		`C:\Program Files (x86)\Steam`,
-		`C:\Program Files\Steam`,
-		`D:\Steam`,
-		`E:\Steam`,
+	// 3. Check environment variable
+	if envPath := os.Getenv("STEAM_PATH"); envPath != "" {
+		if sd.validateSteamPath(envPath) {
+			return envPath, nil
+		}
 	}
 
-	for _, path := range defaultPaths {
+	// 4. Sweep common locations on each drive
+	for _, path := range sd.candidateSteamPaths() {
 		if sd.validateSteamPath(path) {
 			return path, nil
 		}
 	}
 
-	// 4. Check environment variable
-	if envPath := os.Getenv("STEAM_PATH"); envPath != "" {
-		if sd.validateSteamPath(envPath) {
-			return envPath, nil
+	return "", fmt.Errorf("steam installation not found")
+}
+
+// candidateSteamPaths lists the usual Steam locations on each drive to sweep
+func (sd *SteamDetector) candidateSteamPaths() []string {
+	drives := sd.searchDrives()
+
+	var paths []string
+	for _, drive := range drives {
+		root := drive + `:\`
+		paths = append(paths,
+			filepath.Join(root, "Program Files (x86)", "Steam"),
+			filepath.Join(root, "Program Files", "Steam"),
+			filepath.Join(root, "Steam"),
+			filepath.Join(root, "SteamLibrary"),
+		)
+	}
+
+	return paths
+}
+
+// searchDrives returns the drive letters to sweep: the configured list if set,
+// otherwise every fixed drive reported by Windows
+func (sd *SteamDetector) searchDrives() []string {
+	if sd.config.Steam != nil && len(sd.config.Steam.SearchDrives) > 0 {
+		drives := make([]string, 0, len(sd.config.Steam.SearchDrives))
+		for _, drive := range sd.config.Steam.SearchDrives {
+			drive = strings.ToUpper(strings.TrimRight(drive, `:\/`))
+			if len(drive) == 1 && drive[0] >= 'A' && drive[0] <= 'Z' {
+				drives = append(drives, drive)
+			} else {
+				logDebugf("⚠️ Ignoring invalid search drive: %q\n", drive)
+			}
 		}
+		return drives
 	}
 
-	return "", fmt.Errorf("steam installation not found")
+	return fixedDrives()
+}
+
+// fixedDrives enumerates local fixed drives via GetLogicalDrives/GetDriveTypeW
+func fixedDrives() []string {
+	mask, err := windows.GetLogicalDrives()
+	if err != nil {
+		logDebugf("⚠️ Could not enumerate drives: %v\n", err)
+		return []string{"C"}
+	}
+
+	var drives []string
+	for i := 0; i < 26; i++ {
+		if mask&(1<<uint(i)) == 0 {
+			continue
+		}
+
+		letter := string(rune('A' + i))
+		root, err := windows.UTF16PtrFromString(letter + `:\`)
+		if err != nil {
+			continue
+		}
+
+		if windows.GetDriveType(root) == windows.DRIVE_FIXED {
+			drives = append(drives, letter)
+		}
+	}
+
+	logDebugf("💽 Fixed drives to search: %s\n", strings.Join(drives, ", "))
+
+	return drives
 }
 
 // getSteamPathFromRegistry retrieves Steam path from Windows registry
@@ -139,20 +207,14 @@ func (sd *SteamDetector) DiscoverLibraries(steamPath string) ([]Library, error)
 	// Parse libraryfolders.vdf for additional libraries
 	libraryFoldersPath := filepath.Join(steamPath, "steamapps", "libraryfolders.vdf")
 
-	content, err := os.ReadFile(libraryFoldersPath)
+	libraryData, err := readLibraryFolders(libraryFoldersPath)
 	if err != nil {
-		// If libraryfolders.vdf doesn't exist, just return main library
-		if verbose {
-			fmt.Printf("⚠️ Could not read libraryfolders.vdf: %v\n", err)
-		}
-		return libraries, nil
-	}
-
-	parser := NewVDFParser()
-	libraryData, err := parser.ParseLibraryFolders(content)
-	if err != nil {
-		if verbose {
-			fmt.Printf("⚠️ Error parsing libraryfolders.vdf: %v\n", err)
+		if os.IsNotExist(err) {
+			// If libraryfolders.vdf doesn't exist, just return main library
+			logDebugf("⚠️ Could not read libraryfolders.vdf: %v\n", err)
+		} else {
+			logWarnf("⚠️ Could not read libraryfolders.vdf, additional libraries were skipped and only the main library will be scanned: %v\n", err)
+			logWarnf("💡 Steam may be installing or updating a game; rerun the scan once it finishes\n")
 		}
 		return libraries, nil
 	}
@@ -170,11 +232,15 @@ func (sd *SteamDetector) DiscoverLibraries(steamPath string) ([]Library, error)
 			continue
 		}
 
+		// Steam marks libraries on unavailable drives with mounted "0"
+		if libInfo.Mounted == "0" && !sd.includeUnmounted() {
+			logDebugf("⏏️ Skipping unmounted library: %s\n", libInfo.Path)
+			continue
+		}
+
 		// Validate library path exists and is accessible
 		if !sd.validateLibraryPath(libInfo.Path) {
-			if verbose {
-				fmt.Printf("⚠️ Skipping inaccessible library: %s\n", libInfo.Path)
-			}
+			logDebugf("⚠️ Skipping inaccessible library: %s\n", libInfo.Path)
 			continue
 		}
 
@@ -195,16 +261,69 @@ func (sd *SteamDetector) DiscoverLibraries(steamPath string) ([]Library, error)
 		libraries = append(libraries, library)
 	}
 
-	if verbose {
-		fmt.Printf("📚 Found %d Steam libraries\n", len(libraries))
-		for _, lib := range libraries {
-			fmt.Printf("   - %s: %s\n", lib.Label, lib.Path)
-		}
+	logDebugf("📚 Found %d Steam libraries\n", len(libraries))
+	for _, lib := range libraries {
+		logDebugf("   - %s: %s\n", lib.Label, lib.Path)
 	}
 
 	return libraries, nil
 }
 
+// includeUnmounted reports whether libraries flagged as unmounted should still be scanned
+func (sd *SteamDetector) includeUnmounted() bool {
+	return unmounted || (sd.config.Steam != nil && sd.config.Steam.Unmounted)
+}
+
+// readLibraryFolders reads and parses libraryfolders.vdf, retrying with a short
+// backoff while Steam holds the file locked or has only partially written it
+func readLibraryFolders(path string) (map[string]LibraryInfo, error) {
+	var lastErr error
+	for attempt := 1; attempt <= libraryFoldersAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * libraryFoldersRetryDelay)
+		}
+
+		content, err := os.ReadFile(path)
+		switch {
+		case err != nil && !isTransientReadError(err):
+			return nil, err
+		case err != nil:
+			lastErr = err
+		case !vdfBalanced(content):
+			lastErr = fmt.Errorf("file looks partially written")
+		default:
+			libraryData, err := NewVDFParser().ParseLibraryFolders(content)
+			if err == nil {
+				return libraryData, nil
+			}
+			lastErr = err
+		}
+		logDebugf("⏳ libraryfolders.vdf not readable yet (attempt %d/%d): %v\n", attempt, libraryFoldersAttempts, lastErr)
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", libraryFoldersAttempts, lastErr)
+}
+
+// isTransientReadError reports whether err comes from another process holding the file
+func isTransientReadError(err error) bool {
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+}
+
+// vdfBalanced reports whether content is non-empty and every brace is closed,
+// which a file Steam is still writing usually fails
+func vdfBalanced(content []byte) bool {
+	depth, sawBrace := 0, false
+	for _, b := range content {
+		switch b {
+		case '{':
+			depth++
+			sawBrace = true
+		case '}':
+			depth--
+		}
+	}
+	return sawBrace && depth == 0
+}
+
 // validateLibraryPath checks if a library path is valid and accessible
 func (sd *SteamDetector) validateLibraryPath(path string) bool {
 	if path == "" {
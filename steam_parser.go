@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -35,6 +36,9 @@ type GameInfo struct {
 	InstallDir      string `json:"installdir"`
 }
 
+// utf8BOM is the byte order mark some tools prepend to VDF/ACF files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
 // NewVDFParser creates a new VDF parser instance
 func NewVDFParser() *VDFParser {
 	return &VDFParser{}
@@ -44,8 +48,7 @@ func NewVDFParser() *VDFParser {
 func (p *VDFParser) ParseLibraryFolders(content []byte) (map[string]LibraryInfo, error) {
 	libraries := make(map[string]LibraryInfo)
 
-
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	scanner := bufio.NewScanner(bytes.NewReader(stripBOM(content)))
 	var currentLibrary *LibraryInfo
 	var currentKey string
 	var inLibrarySection bool
@@ -98,9 +101,9 @@ func (p *VDFParser) ParseLibraryFolders(content []byte) (map[string]LibraryInfo,
 			if inLibrarySection && currentLibrary != nil {
 				switch strings.ToLower(key) {
 				case "path":
-					currentLibrary.Path = value
+					currentLibrary.Path = unescapeVDFValue(value)
 				case "label":
-					currentLibrary.Label = value
+					currentLibrary.Label = unescapeVDFValue(value)
 				case "mounted":
 					currentLibrary.Mounted = value
 				case "contentstatsdid":
@@ -132,8 +135,8 @@ func (p *VDFParser) ParseLibraryFolders(content []byte) (map[string]LibraryInfo,
 func (p *VDFParser) ParseAppManifest(content []byte) (GameInfo, error) {
 	var gameInfo GameInfo
 
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
-	keyValuePattern := regexp.MustCompile(`^\s*"([^"]+)"\s*"([^"]*)"`)
+	scanner := bufio.NewScanner(bytes.NewReader(stripBOM(content)))
+	keyValuePattern := regexp.MustCompile(`^\s*"([^"]+)"\s*"((?:[^"\\]|\\.)*)"`)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -153,7 +156,7 @@ func (p *VDFParser) ParseAppManifest(content []byte) (GameInfo, error) {
 			case "universe":
 				gameInfo.Universe = value
 			case "name":
-				gameInfo.Name = value
+				gameInfo.Name = unescapeVDFValue(value)
 			case "stateflags":
 				gameInfo.StateFlags = value
 			case "lastupdated":
@@ -171,7 +174,7 @@ func (p *VDFParser) ParseAppManifest(content []byte) (GameInfo, error) {
 			case "bytesdownloaded":
 				gameInfo.BytesDownloaded = value
 			case "installdir":
-				gameInfo.InstallDir = value
+				gameInfo.InstallDir = unescapeVDFValue(value)
 			}
 		}
 	}
@@ -188,6 +191,19 @@ func (p *VDFParser) ParseAppManifest(content []byte) (GameInfo, error) {
 	return gameInfo, nil
 }
 
+// stripBOM removes a leading UTF-8 byte order mark so the first line parses normally
+func stripBOM(content []byte) []byte {
+	return bytes.TrimPrefix(content, utf8BOM)
+}
+
+// unescapeVDFValue resolves the backslash escapes VDF uses inside quoted values
+func unescapeVDFValue(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value)
+}
+
 // ParseSizeOnDisk converts the SizeOnDisk string to bytes
 func (p *VDFParser) ParseSizeOnDisk(sizeStr string) (int64, error) {
 	if sizeStr == "" {
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFixture loads a file from testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// TestParseAppManifestFixture parses a manifest saved with a UTF-8 BOM whose
+// name has escaped quotes and whose install dir has spaces and non-ASCII letters
+func TestParseAppManifestFixture(t *testing.T) {
+	info, err := NewVDFParser().ParseAppManifest(readFixture(t, "appmanifest_1234560.acf"))
+	if err != nil {
+		t.Fatalf("ParseAppManifest: %v", err)
+	}
+
+	if info.AppID != "1234560" {
+		t.Errorf("AppID = %q, want 1234560", info.AppID)
+	}
+	if want := `Café "Noir" Édition`; info.Name != want {
+		t.Errorf("Name = %q, want %q", info.Name, want)
+	}
+	if want := "Ōkami HD – Édition Spéciale"; info.InstallDir != want {
+		t.Errorf("InstallDir = %q, want %q", info.InstallDir, want)
+	}
+	if info.SizeOnDisk != "2147483648" {
+		t.Errorf("SizeOnDisk = %q, want 2147483648", info.SizeOnDisk)
+	}
+}
+
+// TestParseLibraryFoldersFixture parses a libraryfolders.vdf saved with a UTF-8
+// BOM, which used to hide every library behind the mangled first line
+func TestParseLibraryFoldersFixture(t *testing.T) {
+	libraries, err := NewVDFParser().ParseLibraryFolders(readFixture(t, "libraryfolders_bom.vdf"))
+	if err != nil {
+		t.Fatalf("ParseLibraryFolders: %v", err)
+	}
+
+	if len(libraries) != 2 {
+		t.Fatalf("got %d libraries, want 2: %+v", len(libraries), libraries)
+	}
+	if want := `D:\Jogos Steam\Bibliothèque`; libraries["1"].Path != want {
+		t.Errorf("library 1 path = %q, want %q", libraries["1"].Path, want)
+	}
+	if libraries["1"].Label != "Jogos" {
+		t.Errorf("library 1 label = %q, want Jogos", libraries["1"].Label)
+	}
+}
+
+// TestScanFindsFixtureGame checks the fixture manifest yields a game in a scan
+// instead of being skipped
+func TestScanFindsFixtureGame(t *testing.T) {
+	libraryPath := t.TempDir()
+	writeTestFile(t, filepath.Join(libraryPath, "steamapps", "appmanifest_1234560.acf"), string(readFixture(t, "appmanifest_1234560.acf")))
+	writeTestFile(t, filepath.Join(libraryPath, "steamapps", "common", "Ōkami HD – Édition Spéciale", "okami.exe"), "MZ")
+
+	games, err := NewGameScanner([]Library{{Path: libraryPath, Label: "Games"}}, 1).ScanAllLibraries()
+	if err != nil {
+		t.Fatalf("ScanAllLibraries: %v", err)
+	}
+	if len(games) != 1 || games[0].Executable != "okami.exe" {
+		t.Errorf("scan found %+v, want the fixture game with okami.exe", games)
+	}
+}
@@ -1,28 +1,111 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // GameScanner handles scanning Steam libraries for games
 type GameScanner struct {
 	libraries []Library
 	parser    *VDFParser
+
+	// slots bounds how many games are processed at once across all libraries
+	slots chan struct{}
+
+	// onLibrary, when set, receives each library's games as soon as it finishes
+	// instead of collecting them all; calls are serialized
+	onLibrary   func(library Library, games []Game)
+	onLibraryMu sync.Mutex
+
+	// extraSubdirs are exe_search_subdirs from the config, searched after the built-in ones
+	extraSubdirs []string
+
+	// nameOverrides replaces manifest names by AppID, for codenamed or abbreviated titles
+	nameOverrides map[string]string
+
+	// cache, when set, serves unchanged manifests without re-resolving them
+	cache *manifestCache
 }
 
-// NewGameScanner creates a new game scanner instance
-func NewGameScanner(libraries []Library) *GameScanner {
+// defaultScanThreads caps scan parallelism when --threads isn't given
+func defaultScanThreads() int {
+	return min(runtime.NumCPU(), 4)
+}
+
+// ScanResult is a standalone record of a scan, written by scan-steam --output
+type ScanResult struct {
+	SteamPath string    `yaml:"steam_path" json:"steam_path"`
+	ScannedAt time.Time `yaml:"scanned_at" json:"scanned_at"`
+	Libraries []Library `yaml:"libraries" json:"libraries"`
+	Games     []Game    `yaml:"games" json:"games"`
+}
+
+// WriteScanResult saves the scan result as JSON when the file ends in .json, YAML otherwise
+func WriteScanResult(filename string, result ScanResult) error {
+	var data []byte
+	var err error
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		data, err = json.MarshalIndent(result, "", "  ")
+	} else {
+		data, err = yaml.Marshal(result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan result: %w", err)
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+// NewGameScanner creates a new game scanner that processes at most threads games concurrently
+func NewGameScanner(libraries []Library, threads int) *GameScanner {
+	if threads <= 0 {
+		threads = defaultScanThreads()
+	}
+
 	return &GameScanner{
 		libraries: libraries,
 		parser:    NewVDFParser(),
+		slots:     make(chan struct{}, threads),
 	}
 }
 
+// SetSearchSubdirs adds install-relative folders to search for executables,
+// for engines that keep them somewhere the built-in list doesn't cover
+func (gs *GameScanner) SetSearchSubdirs(subdirs []string) {
+	gs.extraSubdirs = subdirs
+}
+
+// SetNameOverrides replaces the manifest name of the given AppIDs, both in the
+// results and when guessing executable names
+func (gs *GameScanner) SetNameOverrides(overrides map[string]string) {
+	gs.nameOverrides = overrides
+}
+
+// UseManifestCache reuses results for manifests unchanged since the last scan,
+// saved in the file at path. Call it after the other setters. With fresh, the
+// cache is rebuilt from scratch instead of read.
+func (gs *GameScanner) UseManifestCache(path string, fresh bool) {
+	gs.cache = loadManifestCache(path, scanSettings(gs.extraSubdirs, gs.nameOverrides), fresh)
+}
+
+// StreamLibraries makes ScanAllLibraries hand each library's games to fn as the
+// library finishes, rather than returning them all at the end
+func (gs *GameScanner) StreamLibraries(fn func(library Library, games []Game)) {
+	gs.onLibrary = fn
+}
+
 // ScanAllLibraries scans all Steam libraries for games in parallel
 func (gs *GameScanner) ScanAllLibraries() ([]Game, error) {
 	var wg sync.WaitGroup
@@ -37,10 +120,12 @@ func (gs *GameScanner) ScanAllLibraries() ([]Game, error) {
 
 			games, err := gs.scanLibrary(lib)
 			if err != nil {
-				if verbose {
-					fmt.Printf("⚠️ Error scanning library %s: %v\n", lib.Label, err)
-				}
+				logDebugf("⚠️ Error scanning library %s: %v\n", lib.Label, err)
 				errorsChan <- fmt.Errorf("library %s: %w", lib.Label, err)
+			} else if gs.onLibrary != nil {
+				gs.onLibraryMu.Lock()
+				gs.onLibrary(lib, games)
+				gs.onLibraryMu.Unlock()
 			} else {
 				gamesChan <- games
 			}
@@ -74,17 +159,88 @@ func (gs *GameScanner) ScanAllLibraries() ([]Game, error) {
 	// Return combined error if any occurred, but still return found games
 	if len(errors) > 0 && verbose {
 		for _, err := range errors {
-			fmt.Printf("⚠️ %v\n", err)
+			logWarnf("⚠️ %v\n", err)
 		}
 	}
 
-	if verbose {
-		fmt.Printf("🎮 Found %d games across all libraries\n", len(allGames))
+	logDebugf("🎮 Found %d games across all libraries\n", len(allGames))
+
+	if gs.cache != nil {
+		if err := gs.cache.save(); err != nil {
+			logWarnf("⚠️ %v\n", err)
+		}
 	}
 
 	return allGames, nil
 }
 
+// FilterRecentGames keeps only games Steam updated within the given window
+func FilterRecentGames(games []Game, since time.Duration) []Game {
+	cutoff := time.Now().Add(-since)
+	recent := make([]Game, 0, len(games))
+
+	for _, game := range games {
+		if game.LastUpdated.IsZero() || game.LastUpdated.Before(cutoff) {
+			continue
+		}
+		recent = append(recent, game)
+	}
+
+	return recent
+}
+
+// FilterGamesBySize drops games below minMB. Games with no reported size are
+// dropped too unless keepUnknown is set.
+func FilterGamesBySize(games []Game, minMB int64, keepUnknown bool) []Game {
+	kept := make([]Game, 0, len(games))
+	for _, game := range games {
+		if game.SizeMB >= minMB || (game.SizeMB == 0 && keepUnknown) {
+			kept = append(kept, game)
+		} else {
+			logDebugf("📏 Skipping %s (%d MB)\n", game.Name, game.SizeMB)
+		}
+	}
+	return kept
+}
+
+// ExcludeGames drops games whose names match any pattern. Patterns containing
+// glob characters are matched against the whole name, others as substrings;
+// both ignore case.
+func ExcludeGames(games []Game, patterns []string) ([]Game, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(strings.ToLower(pattern), ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	kept := make([]Game, 0, len(games))
+	for _, game := range games {
+		if !matchesAnyPattern(game.Name, patterns) {
+			kept = append(kept, game)
+		} else {
+			logDebugf("🚫 Excluding %s\n", game.Name)
+		}
+	}
+
+	return kept, nil
+}
+
+// matchesAnyPattern reports whether name matches one of the exclude patterns
+func matchesAnyPattern(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if strings.ContainsAny(pattern, "*?[") {
+			if matched, _ := filepath.Match(pattern, name); matched {
+				return true
+			}
+		} else if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 // scanLibrary scans a single Steam library for games
 func (gs *GameScanner) scanLibrary(library Library) ([]Game, error) {
 	steamAppsPath := filepath.Join(library.Path, "steamapps")
@@ -102,54 +258,97 @@ func (gs *GameScanner) scanLibrary(library Library) ([]Game, error) {
 	}
 
 	if len(manifests) == 0 {
-		if verbose {
-			fmt.Printf("📂 No games found in library: %s\n", library.Label)
-		}
+		logDebugf("📂 No games found in library: %s\n", library.Label)
 		return []Game{}, nil
 	}
 
-	var games []Game
+	var (
+		games []Game
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+	)
 	commonPath := filepath.Join(steamAppsPath, "common")
 
 	for _, manifestPath := range manifests {
-		game, err := gs.parseGameManifest(manifestPath, library, commonPath)
-		if err != nil {
-			if verbose {
-				fmt.Printf("⚠️ Skipping invalid manifest %s: %v\n", filepath.Base(manifestPath), err)
-			}
-			continue
-		}
+		wg.Add(1)
+		go func(manifestPath string) {
+			defer wg.Done()
 
-		// Verify game installation exists
-		if !gs.verifyGameInstallation(game.InstallPath) {
-			if verbose {
-				fmt.Printf("⚠️ Skipping uninstalled game: %s (path: %s)\n", game.Name, game.InstallPath)
-			}
-			continue
-		}
+			gs.slots <- struct{}{}
+			defer func() { <-gs.slots }()
 
-		// Find main executable
-		executable, err := gs.FindGameExecutable(game.InstallPath, game.Name)
-		if err != nil {
-			if verbose {
-				fmt.Printf("⚠️ Could not find executable for %s: %v\n", game.Name, err)
+			game, ok := gs.scanManifest(manifestPath, library, commonPath)
+			if !ok {
+				return
 			}
-			// Still add the game but without executable
-			game.Executable = ""
-		} else {
-			game.Executable = executable
-		}
 
-		games = append(games, game)
+			mu.Lock()
+			games = append(games, game)
+			mu.Unlock()
+		}(manifestPath)
 	}
+	wg.Wait()
 
-	if verbose {
-		fmt.Printf("📚 Library %s: Found %d games\n", library.Label, len(games))
-	}
+	logDebugf("📚 Library %s: Found %d games\n", library.Label, len(games))
 
 	return games, nil
 }
 
+// scanManifest turns one manifest into a game, resolving its executable.
+// It reports false for manifests that should be skipped.
+func (gs *GameScanner) scanManifest(manifestPath string, library Library, commonPath string) (Game, bool) {
+	if gs.cache == nil {
+		return gs.resolveManifest(manifestPath, library, commonPath)
+	}
+
+	info, err := os.Stat(manifestPath)
+	if err != nil {
+		return gs.resolveManifest(manifestPath, library, commonPath)
+	}
+	if game, ok := gs.cache.lookup(manifestPath, info); ok && gs.verifyGameInstallation(game.InstallPath) {
+		game.Library = library.Label // Labels can change without the manifest changing
+		return game, true
+	}
+
+	game, ok := gs.resolveManifest(manifestPath, library, commonPath)
+	if ok {
+		gs.cache.store(manifestPath, info, game)
+	}
+	return game, ok
+}
+
+// resolveManifest parses a manifest and searches for the game's executable
+func (gs *GameScanner) resolveManifest(manifestPath string, library Library, commonPath string) (Game, bool) {
+	game, err := gs.parseGameManifest(manifestPath, library, commonPath)
+	if err != nil {
+		logDebugf("⚠️ Skipping invalid manifest %s: %v\n", filepath.Base(manifestPath), err)
+		return Game{}, false
+	}
+
+	// Verify game installation exists
+	if !gs.verifyGameInstallation(game.InstallPath) {
+		logDebugf("⚠️ Skipping uninstalled game: %s (path: %s)\n", game.Name, game.InstallPath)
+		return Game{}, false
+	}
+
+	if name, ok := gs.nameOverrides[game.AppID]; ok && name != "" {
+		logDebugf("🏷️ Using name override for %s: %s -> %s\n", game.AppID, game.Name, name)
+		game.Name = name
+	}
+
+	// Find main executable
+	executable, err := gs.FindGameExecutable(game.InstallPath, game.Name)
+	if err != nil {
+		logDebugf("⚠️ Could not find executable for %s: %v\n", game.Name, err)
+		// Still add the game but without executable
+		game.Executable = ""
+	} else {
+		game.Executable = executable
+	}
+
+	return game, true
+}
+
 // parseGameManifest parses a single app manifest file
 func (gs *GameScanner) parseGameManifest(manifestPath string, library Library, commonPath string) (Game, error) {
 	content, err := os.ReadFile(manifestPath)
@@ -170,6 +369,12 @@ func (gs *GameScanner) parseGameManifest(manifestPath string, library Library, c
 		}
 	}
 
+	// LastUpdated is a unix timestamp written by Steam on install/update
+	var lastUpdated time.Time
+	if seconds, err := strconv.ParseInt(gameInfo.LastUpdated, 10, 64); err == nil && seconds > 0 {
+		lastUpdated = time.Unix(seconds, 0)
+	}
+
 	// Build install path
 	installPath := filepath.Join(commonPath, gameInfo.InstallDir)
 
@@ -179,6 +384,7 @@ func (gs *GameScanner) parseGameManifest(manifestPath string, library Library, c
 		InstallPath: installPath,
 		Library:     library.Label,
 		SizeMB:      sizeMB,
+		LastUpdated: lastUpdated,
 	}
 
 	return game, nil
@@ -216,6 +422,9 @@ func (gs *GameScanner) FindGameExecutable(installDir, gameName string) (string,
 		filepath.Join(installDir, "Game", "Binaries", "Win64"),
 		filepath.Join(installDir, "Shipping", "Binaries", "Win64"),
 	}
+	for _, subdir := range gs.extraSubdirs {
+		searchPaths = append(searchPaths, filepath.Join(installDir, filepath.FromSlash(subdir)))
+	}
 
 	for _, searchPath := range searchPaths {
 		if executable := gs.searchExecutableInPath(searchPath, patterns); executable != "" {
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// ubisoftLibraryLabel tags detected games that came from Ubisoft Connect
+const ubisoftLibraryLabel = "ubisoft"
+
+// UbisoftDetector finds games installed through Ubisoft Connect
+type UbisoftDetector struct {
+	scanner *GameScanner
+}
+
+// NewUbisoftDetector creates a detector that resolves executables with the
+// same heuristics as the Steam scanner
+func NewUbisoftDetector() *UbisoftDetector {
+	return &UbisoftDetector{scanner: NewGameScanner(nil, 1)}
+}
+
+// FindGames lists installed Ubisoft Connect games. Install IDs and paths come
+// from the launcher's registry entries; folders in the default games directory
+// that the registry doesn't mention are picked up as well.
+func (ud *UbisoftDetector) FindGames() ([]Game, error) {
+	installs, err := ud.installsFromRegistry()
+	if err != nil && verbose {
+		logWarnf("⚠️ %v\n", err)
+	}
+
+	known := make(map[string]bool, len(installs))
+	for _, dir := range installs {
+		known[strings.ToLower(filepath.Clean(dir))] = true
+	}
+	for _, dir := range ud.defaultGameDirs() {
+		if !known[strings.ToLower(filepath.Clean(dir))] {
+			installs["dir:"+filepath.Base(dir)] = dir
+		}
+	}
+
+	if len(installs) == 0 {
+		return nil, fmt.Errorf("no Ubisoft Connect games found")
+	}
+
+	var games []Game
+	for id, dir := range installs {
+		if !ud.scanner.verifyGameInstallation(dir) {
+			logDebugf("⚠️ Skipping uninstalled Ubisoft game %s (path: %s)\n", id, dir)
+			continue
+		}
+
+		name := filepath.Base(dir)
+		executable, err := ud.scanner.FindGameExecutable(dir, name)
+		if err != nil {
+			logDebugf("⚠️ No executable found for %s: %v\n", name, err)
+			continue
+		}
+
+		games = append(games, Game{
+			Name:        name,
+			AppID:       "uplay:" + id,
+			Executable:  executable,
+			InstallPath: dir,
+			Library:     ubisoftLibraryLabel,
+		})
+		logDebugf("✅ Found Ubisoft game: %s -> %s\n", name, executable)
+	}
+
+	return games, nil
+}
+
+// installsFromRegistry maps install IDs to install directories from
+// HKLM\SOFTWARE\WOW6432Node\Ubisoft\Launcher\Installs
+func (ud *UbisoftDetector) installsFromRegistry() (map[string]string, error) {
+	installs := make(map[string]string)
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Ubisoft\Launcher\Installs`, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return installs, fmt.Errorf("ubisoft connect installs not found in registry: %w", err)
+	}
+	defer key.Close()
+
+	ids, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return installs, fmt.Errorf("failed to list Ubisoft installs: %w", err)
+	}
+
+	for _, id := range ids {
+		sub, err := registry.OpenKey(key, id, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		dir, _, err := sub.GetStringValue("InstallDir")
+		sub.Close()
+		if err != nil || dir == "" {
+			continue
+		}
+
+		// The launcher stores paths with forward slashes and a trailing slash
+		installs[id] = filepath.Clean(strings.ReplaceAll(dir, "/", "\\"))
+	}
+
+	return installs, nil
+}
+
+// defaultGameDirs lists the folders in the launcher's default games directory
+func (ud *UbisoftDetector) defaultGameDirs() []string {
+	root := filepath.Join(os.Getenv("ProgramFiles(x86)"), "Ubisoft", "Ubisoft Game Launcher", "games")
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(root, entry.Name()))
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// InstalledApp is a program found in the Windows uninstall entries that looks like a game
+type InstalledApp struct {
+	Name       string
+	Executable string
+	Path       string
+	SizeMB     int64
+}
+
+// uninstallKeys are the registry locations Windows lists installed programs under
+var uninstallKeys = []struct {
+	root registry.Key
+	path string
+}{
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`},
+	{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`},
+	{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Uninstall`},
+}
+
+// launcherPathMarkers identify installs a launcher scan already covers
+var launcherPathMarkers = []string{
+	`\steamapps\`,
+	`\ubisoft game launcher\`,
+}
+
+// UninstallDetector finds standalone games through the registry's uninstall
+// entries, as a last resort for installs no launcher knows about
+type UninstallDetector struct {
+	scanner *GameScanner
+	minMB   int64
+}
+
+// NewUninstallDetector creates a detector that skips entries smaller than minMB
+func NewUninstallDetector(minMB int64) *UninstallDetector {
+	return &UninstallDetector{scanner: NewGameScanner(nil, 1), minMB: minMB}
+}
+
+// FindApps lists uninstall entries with a display name, an existing install
+// location containing an executable and at least the minimum size
+func (ud *UninstallDetector) FindApps() []InstalledApp {
+	seen := make(map[string]bool)
+	var apps []InstalledApp
+
+	for _, location := range uninstallKeys {
+		key, err := registry.OpenKey(location.root, location.path, registry.ENUMERATE_SUB_KEYS)
+		if err != nil {
+			continue
+		}
+		names, _ := key.ReadSubKeyNames(-1)
+
+		for _, name := range names {
+			app, ok := ud.readEntry(key, name)
+			if !ok || seen[strings.ToLower(app.Path)] {
+				continue
+			}
+			seen[strings.ToLower(app.Path)] = true
+			apps = append(apps, app)
+		}
+		key.Close()
+	}
+
+	sort.Slice(apps, func(i, j int) bool { return strings.ToLower(apps[i].Name) < strings.ToLower(apps[j].Name) })
+	return apps
+}
+
+// readEntry turns one uninstall subkey into a candidate, filtering out
+// components, updates, launcher-managed installs and small programs
+func (ud *UninstallDetector) readEntry(parent registry.Key, subkey string) (InstalledApp, bool) {
+	key, err := registry.OpenKey(parent, subkey, registry.QUERY_VALUE)
+	if err != nil {
+		return InstalledApp{}, false
+	}
+	defer key.Close()
+
+	if component, _, err := key.GetIntegerValue("SystemComponent"); err == nil && component == 1 {
+		return InstalledApp{}, false
+	}
+	if parentName, _, err := key.GetStringValue("ParentKeyName"); err == nil && parentName != "" {
+		return InstalledApp{}, false
+	}
+
+	name, _, err := key.GetStringValue("DisplayName")
+	if err != nil || strings.TrimSpace(name) == "" {
+		return InstalledApp{}, false
+	}
+	location, _, err := key.GetStringValue("InstallLocation")
+	if err != nil || strings.TrimSpace(location) == "" {
+		return InstalledApp{}, false
+	}
+	location = filepath.Clean(strings.Trim(location, `"`))
+
+	lower := strings.ToLower(location) + `\`
+	for _, marker := range launcherPathMarkers {
+		if strings.Contains(lower, marker) {
+			return InstalledApp{}, false
+		}
+	}
+
+	// EstimatedSize is in KB; entries without it are kept and judged by their executable
+	var sizeMB int64
+	if size, _, err := key.GetIntegerValue("EstimatedSize"); err == nil {
+		sizeMB = int64(size) / 1024
+		if sizeMB < ud.minMB {
+			return InstalledApp{}, false
+		}
+	}
+
+	if stat, err := os.Stat(location); err != nil || !stat.IsDir() {
+		return InstalledApp{}, false
+	}
+
+	executable, err := ud.scanner.FindGameExecutable(location, name)
+	if err != nil {
+		return InstalledApp{}, false
+	}
+
+	return InstalledApp{Name: name, Executable: executable, Path: location, SizeMB: sizeMB}, true
+}
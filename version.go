@@ -0,0 +1,58 @@
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time, e.g. -ldflags "-X main.version=1.2.0 -X main.commit=abc1234"
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// SupportedDevice identifies a mouse the binary knows how to talk to
+type SupportedDevice struct {
+	Name      string `json:"name"`
+	VendorID  uint16 `json:"vendor_id"`
+	ProductID uint16 `json:"product_id"`
+	Interface int    `json:"interface"`
+}
+
+// supportedDevices lists the VID/PID combinations discovery looks for
+var supportedDevices = []SupportedDevice{
+	{Name: "LAMZU Maya X 8K", VendorID: LAMZU_VID, ProductID: LAMZU_PID, Interface: INTERFACE_NUMBER},
+}
+
+// BuildInfo describes the running binary for bug reports
+type BuildInfo struct {
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit"`
+	GoVersion string            `json:"go_version"`
+	Devices   []SupportedDevice `json:"supported_devices"`
+}
+
+// buildInfo collects the version details. Without an ldflags commit, the VCS
+// revision Go embeds in module builds is used.
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+		Devices:   supportedDevices,
+	}
+
+	if info.Commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				if setting.Key == "vcs.revision" {
+					info.Commit = setting.Value
+				}
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
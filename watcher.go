@@ -1,90 +1,976 @@
 package main
 
 import (
+	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
+// Sources a watched game can come from
+const (
+	sourceLegacy  = "legacy"
+	sourceSteam   = "steam"
+	sourceEpic    = "epic"
+	sourceUbisoft = "ubisoft"
+	sourceCustom  = "custom"
+	sourceAlias   = "alias"
+	sourceLoad    = "load" // Not a process: the load_trigger firing
+)
+
+// watchedGame is a single monitored executable flattened from the config's game lists
+type watchedGame struct {
+	Name       string
+	Executable string
+	Source     string
+	Rate       int // Per-game polling_rate, 0 for game_polling_rate
+	CloseRate  int
+	Reassert   bool
+	Path       string // Known install path, empty if not recorded yet
+	Parent     string // Required ancestor process, if any
+	// Resolution- and refresh-based rate tiers, if configured
+	ResolutionRates []ResolutionRule
+	RefreshRates    []RefreshRule
+	// Disabled entries are only kept until duplicates are merged
+	Disabled bool
+}
+
+// defaultReassertTicks is used when reassert_ticks isn't configured
+const defaultReassertTicks = 5
+
 type GameWatcher struct {
-	config              *Config
-	mouse               MouseControllerInterface
-	notificationManager *NotificationManager
-	isGameRunning       bool
-	ticker              *time.Ticker
-	stopCh              chan struct{}
-	processCache        []string
+	config          *Config
+	mouse           MouseControllerInterface
+	events          chan WatchEvent
+	isGameRunning   bool
+	runningGames    []watchedGame
+	appliedRate     int
+	targetRate      int    // Rate selected by games/schedule before modifiers
+	selectedGame    string // Executable of the running game whose rate is in effect
+	suspendedBy     string // pause_during process currently holding the rate, if any
+	baseRate        int
+	reassertCounter int
+	modifier        *ModifierRule
+	ticker          *time.Ticker
+	reconcileTicker *time.Ticker
+	stopCh          chan struct{}
+	doneCh          chan struct{} // Closed when the current monitoring loop exits
+	watchdogDone    chan struct{}
+	reconcileCh     <-chan time.Time
+	loopMu          sync.Mutex
+	loopGen         int                           // Bumped when the watchdog replaces a stuck loop
+	stateMu         sync.Mutex                    // Held by every tick, so a replaced loop and its successor never run at once
+	status          atomic.Pointer[watcherStatus] // State after the last completed tick, for readers that can't get stateMu
+	eventsMu        sync.Mutex
+	eventsClosed    bool         // Set by Stop; later emits from an abandoned loop are dropped
+	lastTick        atomic.Int64 // UnixNano of the last completed tick
+	processCache    []string
+	counters        watcherCounters
+	recordedPaths   map[string]bool      // Executables whose path was already looked up this run
+	lastSeen        map[string]time.Time // Sightings not yet written to the config
+	lastSeenFlushed time.Time
+	configModTime   time.Time                // Config file version the device profile was read from
+	processSource   func() ([]string, error) // Lists running process names; swapped out by simulate
+	recordSightings bool                     // Persist game paths and last-seen times
+	unresolvedSeen  map[string]bool          // Unresolved games already reported this run
+	observeOnly     bool                     // Only read the device rate and report changes, never write
+	load            *loadMonitor             // Set when load_trigger is configured
+	focusGame       string                   // detection_mode foreground: match key of the game last in focus
+	focusLeft       time.Time                // When focus moved from focusGame to another app
+	paused          atomic.Bool
 }
 
-func NewGameWatcher(config *Config, mouse MouseControllerInterface, notificationManager *NotificationManager) *GameWatcher {
-	return &GameWatcher{
-		config:              config,
-		mouse:               mouse,
-		notificationManager: notificationManager,
-		stopCh:              make(chan struct{}),
+func NewGameWatcher(config *Config, mouse MouseControllerInterface) *GameWatcher {
+	gw := &GameWatcher{
+		config:         config,
+		mouse:          newTimeoutMouse(mouse, deviceCallTimeout),
+		events:         make(chan WatchEvent, watchEventBuffer),
+		appliedRate:    capPollingRate(scheduledRate(config, time.Now())),
+		targetRate:     scheduledRate(config, time.Now()),
+		baseRate:       scheduledRate(config, time.Now()),
+		stopCh:         make(chan struct{}),
+		doneCh:         make(chan struct{}),
+		recordedPaths:  make(map[string]bool),
+		lastSeen:       make(map[string]time.Time),
+		configModTime:  configModTime(configFile),
+		unresolvedSeen: make(map[string]bool),
+	}
+	gw.processSource = gw.getRunningProcesses
+	gw.recordSightings = true
+	if config.LoadTrigger != nil {
+		gw.load = newLoadMonitor(*config.LoadTrigger)
 	}
+	return gw
 }
 
 func (gw *GameWatcher) Start() {
+	gw.counters.mu.Lock()
+	gw.counters.started = time.Now()
+	gw.counters.mu.Unlock()
 	gw.ticker = time.NewTicker(gw.config.CheckInterval)
 
+	// Read-back checks run on their own interval, independent of process
+	// checks. They correct the device, so observe mode never runs them.
+	if gw.config.ReconcileInterval > 0 && !gw.observeOnly {
+		gw.reconcileTicker = time.NewTicker(gw.config.ReconcileInterval)
+		gw.reconcileCh = gw.reconcileTicker.C
+	}
+
+	logDuplicateGames(gw.config)
+
+	gw.runLoop()
+	gw.watchdogDone = make(chan struct{})
+	go gw.watchdog()
+
+	// Initial check
+	gw.stateMu.Lock()
+	defer gw.stateMu.Unlock()
+	defer gw.publishStatus()
+	if gw.observeOnly {
+		gw.safely(gw.observeRate)
+		return
+	}
+	gw.safely(gw.checkProcesses)
+}
+
+// watcherStatus is what GetStatus and Metrics report about the watcher's state
+type watcherStatus struct {
+	running bool
+	rate    int
+	game    string
+}
+
+// publishStatus records the current state for currentStatus; stateMu must be held
+func (gw *GameWatcher) publishStatus() {
+	status := watcherStatus{running: gw.isGameRunning, rate: gw.appliedRate}
+	if len(gw.runningGames) > 0 {
+		status.game = gw.runningGames[0].Name
+	}
+	gw.status.Store(&status)
+}
+
+// currentStatus reads the live state, or the state after the last completed
+// tick while a check holds stateMu, so callers never wait on a stuck check
+func (gw *GameWatcher) currentStatus() watcherStatus {
+	if gw.stateMu.TryLock() {
+		defer gw.stateMu.Unlock()
+		gw.publishStatus()
+	}
+	if status := gw.status.Load(); status != nil {
+		return *status
+	}
+	return watcherStatus{rate: gw.config.DefaultPollingRate}
+}
+
+// stopStateWait bounds how long Stop waits for a stuck check to release the
+// watcher state before giving up on the final last-seen flush
+const stopStateWait = 2 * time.Second
+
+// lockStateWithin takes stateMu unless it stays held for longer than wait
+func (gw *GameWatcher) lockStateWithin(wait time.Duration) bool {
+	deadline := time.Now().Add(wait)
+	for !gw.stateMu.TryLock() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// runLoop starts a monitoring loop goroutine. Ticks hold stateMu and check the
+// loop is still current first. A loop replaced by the watchdog may still hold
+// stateMu in a stuck check; the new loop skips its ticks rather than queue
+// behind it, and the old loop exits without touching state once it returns.
+func (gw *GameWatcher) runLoop() {
+	gw.loopMu.Lock()
+	gw.loopGen++
+	gen := gw.loopGen
+	done := make(chan struct{})
+	gw.doneCh = done
+	gw.loopMu.Unlock()
+
+	gw.lastTick.Store(time.Now().UnixNano())
+	current := func() bool {
+		gw.loopMu.Lock()
+		defer gw.loopMu.Unlock()
+		return gen == gw.loopGen
+	}
+
+	// tick runs work unless the loop has been replaced or a stuck check
+	// still holds the state, reporting whether it ran
+	tick := func(work ...func()) bool {
+		if !gw.stateMu.TryLock() {
+			logDebugf("⏭️ Skipping check, a previous check is still running\n")
+			return false
+		}
+		defer gw.stateMu.Unlock()
+		if !current() {
+			return false
+		}
+		for _, fn := range work {
+			gw.safely(fn)
+		}
+		gw.publishStatus()
+		return true
+	}
+
 	go func() {
+		defer close(done)
 		for {
+			var ran bool
 			select {
 			case <-gw.ticker.C:
-				gw.checkProcesses()
+				if gw.observeOnly {
+					ran = tick(gw.observeRate)
+				} else {
+					ran = tick(gw.reloadDeviceProfile, gw.checkProcesses)
+				}
+			case <-gw.reconcileCh:
+				ran = tick(gw.reconcileRate)
 			case <-gw.stopCh:
 				return
 			}
+
+			if !current() {
+				return
+			}
+			if ran {
+				gw.lastTick.Store(time.Now().UnixNano())
+			}
 		}
 	}()
+}
 
-	// Initial check
-	gw.checkProcesses()
+// Watchdog limits: a loop is considered stuck after this many check intervals
+// without a completed tick, but never sooner than watchdogMinStall
+const (
+	watchdogMissedTicks = 5
+	watchdogMinStall    = 30 * time.Second
+)
+
+// watchdog restarts the monitoring loop when ticks stop completing, e.g. when a
+// check hangs outside the device calls, which time out on their own. It
+// complements safely, which only catches panics. A stall is reported once,
+// however many restarts it takes to clear.
+func (gw *GameWatcher) watchdog() {
+	defer close(gw.watchdogDone)
+
+	limit := max(watchdogMissedTicks*gw.config.CheckInterval, watchdogMinStall)
+	ticker := time.NewTicker(gw.config.CheckInterval)
+	defer ticker.Stop()
+
+	var restartedAt int64 // lastTick as set by the last restart
+	for {
+		select {
+		case <-ticker.C:
+			last := gw.lastTick.Load()
+			stalled := time.Since(time.Unix(0, last))
+			if stalled < limit {
+				continue
+			}
+			if last == restartedAt {
+				logDebugf("⚠️ Monitoring still stalled, restarting the loop again\n")
+				gw.runLoop()
+				restartedAt = gw.lastTick.Load()
+				continue
+			}
+			gw.counters.add(&gw.counters.errors)
+			logErrorf("❌ No watcher check completed for %s, restarting the monitoring loop\n", stalled.Round(time.Second))
+			gw.emit(WatchEvent{Type: EventError, Failed: failedWatchdog, Err: fmt.Errorf("monitoring stalled for %s", stalled.Round(time.Second))})
+			gw.runLoop()
+			restartedAt = gw.lastTick.Load()
+		case <-gw.stopCh:
+			return
+		}
+	}
+}
+
+// safely runs one unit of watcher work, turning a panic into an error report
+// and a fall back to the default rate so the loop keeps running
+func (gw *GameWatcher) safely(work func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			gw.counters.add(&gw.counters.errors)
+			logErrorf("❌ Watcher panic: %v (falling back to %dHz)\n", r, gw.config.DefaultPollingRate)
+			logDebugf("%s\n", debug.Stack())
+			gw.emit(WatchEvent{Type: EventError, Failed: failedWatcher, Rate: gw.config.DefaultPollingRate, Err: fmt.Errorf("panic: %v", r)})
+
+			if err := gw.mouse.SetPollingRate(gw.config.DefaultPollingRate); err == nil {
+				gw.appliedRate = capPollingRate(gw.config.DefaultPollingRate)
+				gw.targetRate = gw.config.DefaultPollingRate
+			}
+			// Forget game state so the next check re-detects from scratch
+			gw.isGameRunning = false
+			gw.runningGames = nil
+			gw.selectedGame = ""
+		}
+	}()
+
+	work()
 }
 
+// Stop halts monitoring and waits for the current loop's in-flight check to
+// finish. A loop abandoned by the watchdog may still be stuck; it exits on its
+// own and its events are dropped once the stream is closed.
 func (gw *GameWatcher) Stop() {
 	if gw.ticker != nil {
 		gw.ticker.Stop()
 	}
+	if gw.reconcileTicker != nil {
+		gw.reconcileTicker.Stop()
+	}
 	close(gw.stopCh)
+	if gw.watchdogDone != nil {
+		<-gw.watchdogDone
+	}
+	gw.loopMu.Lock()
+	done := gw.doneCh
+	gw.loopMu.Unlock()
+	<-done
+
+	if gw.lockStateWithin(stopStateWait) {
+		if gw.load != nil {
+			gw.load.close()
+		}
+		gw.flushLastSeen()
+		gw.stateMu.Unlock()
+	} else {
+		logWarnf("⚠️ A stuck check still holds the watcher state, last-seen times were not saved\n")
+	}
+
+	gw.eventsMu.Lock()
+	gw.eventsClosed = true
+	close(gw.events)
+	gw.eventsMu.Unlock()
+}
+
+// reloadDeviceProfile re-reads the device section when the config file changes
+// and hands the rebuilt profile to the controller, so command format tweaks
+// apply on the next rate switch without a restart
+func (gw *GameWatcher) reloadDeviceProfile() {
+	if noConfig {
+		return
+	}
+
+	modTime := configModTime(configFile)
+	if modTime.IsZero() || modTime.Equal(gw.configModTime) {
+		return
+	}
+	gw.configModTime = modTime
+
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		logWarnf("⚠️ Config changed but could not be read, keeping the device profile: %v\n", err)
+		return
+	}
+	if reflect.DeepEqual(config.Device, gw.config.Device) {
+		return
+	}
+
+	profile := defaultDeviceProfile
+	if config.Device != nil {
+		if problems := validateDeviceProfile(config.Device); len(problems) > 0 {
+			logWarnf("⚠️ Ignoring changed device section: %v\n", problems[0])
+			return
+		}
+		profile = *config.Device
+	}
+	if slotFlag != "" {
+		profile.Slot = slotFlag
+	}
+
+	applyRateBytes(profile.RateBytes)
+	gw.mouse.SetProfile(profile)
+	gw.config.Device = config.Device
+	logInfof("🔧 Reloaded device profile %q (report ID 0x%02X, slot %q)\n", profile.Name, profile.ReportID, profile.Slot)
 }
 
 func (gw *GameWatcher) checkProcesses() {
-	runningProcesses, err := gw.getRunningProcesses()
+	if gw.paused.Load() {
+		return
+	}
+
+	gw.counters.add(&gw.counters.checks)
+
+	runningProcesses, err := gw.processSource()
 	if err != nil {
-		if verbose {
-			fmt.Printf("❌ Error getting processes: %v\n", err)
+		gw.counters.add(&gw.counters.errors)
+		logDebugf("❌ Error getting processes: %v\n", err)
+		return
+	}
+
+	processSet := buildProcessSet(gw.config, runningProcesses)
+
+	// pause_during apps suspend every HID write; state is re-evaluated once they exit
+	if blocker := pausingProcess(gw.config, processSet); blocker != "" {
+		if gw.suspendedBy == "" {
+			logInfof("⏸️ %s is running, holding the current rate\n", blocker)
 		}
+		gw.suspendedBy = blocker
 		return
 	}
+	if gw.suspendedBy != "" {
+		logInfof("▶️ %s exited, resuming switching\n", gw.suspendedBy)
+		gw.suspendedBy = ""
+	}
 
-	gameRunning := gw.isAnyGameRunning(runningProcesses)
+	modifierChanged := gw.updateModifier(processSet)
+
+	running := gw.findRunningGames(processSet)
+	if gw.config.DetectionMode == detectionForeground {
+		running = gw.focusedGames(running)
+	}
+	if gw.load != nil && gw.load.update(time.Now()) {
+		running = append(running, watchedGame{Name: loadGameName, Source: sourceLoad})
+	}
+	gameRunning := len(running) > 0
 
 	if gameRunning && !gw.isGameRunning {
-		fmt.Printf("🎮 Game detected! Switching to %dHz\n", gw.config.GamePollingRate)
+		gw.reassertCounter = 0
+		game, gameRate := gw.selectGame(running)
+		gw.selectedGame = game.Executable
+		gw.targetRate = gameRate
+		rate := gw.clampRate(gw.targetRate)
+		logInfof("🎮 Game detected (%s, %s)! Switching to %dHz\n", game.Name, game.Source, rate)
 		gw.isGameRunning = true
-		if err := gw.mouse.SetPollingRate(gw.config.GamePollingRate); err != nil {
-			fmt.Printf("❌ Failed to set game polling rate: %v\n", err)
-			gw.notificationManager.ShowError("Erro", "Falha ao alterar polling rate para jogo")
+		if err := gw.applyRate(rate); errors.Is(err, errSuperseded) {
+			// Another caller's newer rate won; nothing was applied for the game
+		} else if err != nil {
+			logErrorf("❌ Failed to set game polling rate: %v\n", err)
+			gw.emit(WatchEvent{Type: EventError, Failed: failedGameRate, Game: game.Name, Source: game.Source, Rate: rate, Err: err})
 		} else {
-			// Show game detected notification
-			gw.notificationManager.ShowGameDetected(gw.config.GamePollingRate)
+			gw.appliedRate = rate
+			gw.emit(WatchEvent{Type: EventGameDetected, Game: game.Name, Source: game.Source, Rate: rate})
+			runSwitchHooks(gw.config, rate, game.Name)
 		}
 	} else if !gameRunning && gw.isGameRunning {
-		fmt.Printf("🏠 No game detected. Switching to %dHz\n", gw.config.DefaultPollingRate)
+		gw.targetRate = gw.closeRate(gw.runningGames)
+		gw.selectedGame = ""
+		rate := gw.clampRate(gw.targetRate)
+		logInfof("🏠 No game detected. Switching to %dHz\n", rate)
 		gw.isGameRunning = false
-		if err := gw.mouse.SetPollingRate(gw.config.DefaultPollingRate); err != nil {
-			fmt.Printf("❌ Failed to set default polling rate: %v\n", err)
-			gw.notificationManager.ShowError("Erro", "Falha ao alterar polling rate padrão")
+		if err := gw.applyRate(rate); errors.Is(err, errSuperseded) {
+			// Another caller's newer rate won; nothing was applied on close
+		} else if err != nil {
+			logErrorf("❌ Failed to set default polling rate: %v\n", err)
+			gw.emit(WatchEvent{Type: EventError, Failed: failedDefaultRate, Rate: rate, Err: err})
+		} else {
+			gw.appliedRate = rate
+			gw.emit(WatchEvent{Type: EventGameClosed, Rate: rate})
+			runSwitchHooks(gw.config, rate, "")
+		}
+	} else {
+		if gameRunning {
+			if gamesChanged(gw.runningGames, running) || (gw.config.PreferForegroundGame && len(running) > 1) {
+				gw.reselectGame(running)
+			}
+			gw.reassertGameRate(running)
 		} else {
-			// Show game closed notification
-			gw.notificationManager.ShowGameClosed(gw.config.DefaultPollingRate)
+			gw.applyScheduledRate()
+			gw.checkUnresolvedGames()
+		}
+		if modifierChanged {
+			gw.applyModifiedRate()
+		}
+	}
+
+	if gw.recordSightings {
+		if gw.config.PersistPaths {
+			gw.recordGamePaths(running)
+		}
+		gw.trackLastSeen(running)
+	}
+
+	gw.runningGames = running
+}
+
+// InjectProcess makes the watcher see executable as running, in addition to
+// the real processes, until the returned function is called. Call it before Start.
+func (gw *GameWatcher) InjectProcess(executable string) (remove func()) {
+	var injected atomic.Bool
+	injected.Store(true)
+
+	source := gw.processSource
+	gw.processSource = func() ([]string, error) {
+		processes, err := source()
+		if err != nil || !injected.Load() {
+			return processes, err
+		}
+		return append(processes, executable), nil
+	}
+	return func() { injected.Store(false) }
+}
+
+// checkUnresolvedGames warns once per game when the foreground app lives in
+// the install folder of a scanned game that has no executable, since that
+// game is probably running without the rate switching
+func (gw *GameWatcher) checkUnresolvedGames() {
+	var unresolved []Game
+	for _, games := range gw.config.detectedGameLists() {
+		for _, game := range *games {
+			if game.Executable == "" && game.InstallPath != "" && !gw.unresolvedSeen[game.Name] {
+				unresolved = append(unresolved, game)
+			}
+		}
+	}
+	if len(unresolved) == 0 {
+		return
+	}
+
+	path, _, err := foregroundApp()
+	if err != nil {
+		return
+	}
+
+	for _, game := range unresolved {
+		installDir := strings.ToLower(filepath.Clean(game.InstallPath)) + string(filepath.Separator)
+		if !strings.HasPrefix(strings.ToLower(path), installDir) {
+			continue
+		}
+
+		gw.unresolvedSeen[game.Name] = true
+		logWarnf("⚠️ %s seems to be running (%s) but has no executable configured, so the rate won't switch\n", game.Name, filepath.Base(path))
+		logWarnf("💡 Add it with: lamzu-automator add-game --name %q --exe %q\n", game.Name, filepath.Base(path))
+		source := sourceSteam
+		switch game.Library {
+		case epicLibraryLabel:
+			source = sourceEpic
+		case ubisoftLibraryLabel:
+			source = sourceUbisoft
+		}
+		gw.emit(WatchEvent{Type: EventUnresolvedGame, Game: game.Name, Source: source, Path: path})
+		return
+	}
+}
+
+// recordsSightings reports whether games from source have a config entry that
+// takes last-seen times and install paths
+func recordsSightings(source string) bool {
+	switch source {
+	case sourceSteam, sourceEpic, sourceUbisoft, sourceCustom:
+		return true
+	}
+	return false
+}
+
+// lastSeenFlushInterval batches last-seen writes so a running game doesn't
+// rewrite the config on every check
+const lastSeenFlushInterval = 10 * time.Minute
+
+// trackLastSeen notes the running configured games and writes the sightings
+// out at most once per lastSeenFlushInterval
+func (gw *GameWatcher) trackLastSeen(running []watchedGame) {
+	if noConfig {
+		return
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for _, game := range running {
+		if recordsSightings(game.Source) {
+			gw.lastSeen[game.Executable] = now
+		}
+	}
+
+	if now.Sub(gw.lastSeenFlushed) >= lastSeenFlushInterval {
+		gw.flushLastSeen()
+	}
+}
+
+// flushLastSeen writes pending sightings to the config
+func (gw *GameWatcher) flushLastSeen() {
+	if noConfig || len(gw.lastSeen) == 0 {
+		return
+	}
+
+	if err := newBackgroundConfigUpdater(configFile).RecordLastSeen(gw.lastSeen); err != nil {
+		logWarnf("⚠️ Failed to save last-seen times: %v\n", err)
+		return
+	}
+	logDebugf("💾 Saved last-seen times for %d game(s)\n", len(gw.lastSeen))
+	gw.lastSeen = make(map[string]time.Time)
+	gw.lastSeenFlushed = time.Now()
+}
+
+// recordGamePaths saves the resolved install directory of running games that
+// don't have a path in the config yet, at most once per executable per run
+func (gw *GameWatcher) recordGamePaths(running []watchedGame) {
+	if noConfig {
+		return
+	}
+
+	for _, game := range running {
+		if game.Path != "" || !recordsSightings(game.Source) {
+			continue
+		}
+
+		key := strings.ToLower(game.Executable)
+		if gw.recordedPaths[key] {
+			continue
+		}
+		gw.recordedPaths[key] = true
+
+		imagePath, err := processImagePath(game.Executable)
+		if err != nil {
+			logDebugf("⚠️ Could not resolve path for %s: %v\n", game.Name, err)
+			continue
+		}
+
+		dir := filepath.Dir(imagePath)
+		if err := newBackgroundConfigUpdater(configFile).RecordGamePath(game.Executable, dir); err != nil {
+			logWarnf("⚠️ Failed to save path for %s: %v\n", game.Name, err)
+			continue
+		}
+		logDebugf("💾 Saved path for %s: %s\n", game.Name, dir)
+	}
+}
+
+// selectGame picks the running game whose rate applies. The highest requested
+// rate wins, or with prefer_foreground_game the focused game when it's one of them.
+func (gw *GameWatcher) selectGame(running []watchedGame) (watchedGame, int) {
+	if len(running) == 1 {
+		return running[0], gw.gameRate(running[0])
+	}
+
+	if gw.config.PreferForegroundGame {
+		if game, ok := gw.foregroundGame(running); ok {
+			rate := gw.gameRate(game)
+			if game.Executable != gw.selectedGame {
+				logInfof("🎯 %d games running, using foreground game %s (%s, %dHz)\n", len(running), game.Name, game.Source, rate)
+			}
+			return game, rate
+		}
+	}
+
+	best, bestRate := running[0], gw.gameRate(running[0])
+	for _, game := range running[1:] {
+		if rate := gw.gameRate(game); rate > bestRate {
+			best, bestRate = game, rate
+		}
+	}
+	if best.Executable != gw.selectedGame {
+		logInfof("🎯 %d games running, using %s (%s, %dHz, highest requested rate)\n", len(running), best.Name, best.Source, bestRate)
+	}
+	return best, bestRate
+}
+
+// focusedGames narrows the running games to the one in the foreground, for
+// detection_mode foreground. Leaving the game only counts once focus stayed on
+// another app for focus_grace; overlays and foreground_ignore apps don't count.
+func (gw *GameWatcher) focusedGames(running []watchedGame) []watchedGame {
+	path, _, err := foregroundApp()
+	if err == nil && !foregroundIgnored(gw.config, filepath.Base(path)) {
+		key := matchKey(gw.config, filepath.Base(path))
+		for _, game := range running {
+			if matchKey(gw.config, game.Executable) == key {
+				gw.focusGame, gw.focusLeft = key, time.Time{}
+				return []watchedGame{game}
+			}
+		}
+		if gw.focusGame != "" && gw.focusLeft.IsZero() {
+			logDebugf("🪟 Focus moved to %s\n", filepath.Base(path))
+			gw.focusLeft = time.Now()
+		}
+	}
+
+	grace := gw.config.FocusGrace
+	if grace == 0 {
+		grace = defaultFocusGrace
+	}
+	if gw.focusGame != "" && (gw.focusLeft.IsZero() || time.Since(gw.focusLeft) < grace) {
+		for _, game := range running {
+			if matchKey(gw.config, game.Executable) == gw.focusGame {
+				return []watchedGame{game}
+			}
+		}
+	}
+
+	gw.focusGame, gw.focusLeft = "", time.Time{}
+	return nil
+}
+
+// foregroundGame returns the running game that owns the foreground window
+func (gw *GameWatcher) foregroundGame(running []watchedGame) (watchedGame, bool) {
+	path, _, err := foregroundApp()
+	if err != nil {
+		return watchedGame{}, false
+	}
+
+	key := matchKey(gw.config, filepath.Base(path))
+	for _, game := range running {
+		if matchKey(gw.config, game.Executable) == key {
+			return game, true
+		}
+	}
+	return watchedGame{}, false
+}
+
+// reselectGame re-evaluates which running game sets the rate after the set of
+// running games (or the focused one) changed, switching if the choice moved
+func (gw *GameWatcher) reselectGame(running []watchedGame) {
+	game, gameRate := gw.selectGame(running)
+	if game.Executable == gw.selectedGame && gameRate == gw.targetRate {
+		return
+	}
+	gw.selectedGame = game.Executable
+	gw.targetRate = gameRate
+
+	rate := gw.clampRate(gameRate)
+	if rate == gw.appliedRate {
+		return
+	}
+
+	logInfof("🎮 Switching to %dHz for %s (%s)\n", rate, game.Name, game.Source)
+	if err := gw.applyRate(rate); err != nil {
+		if !errors.Is(err, errSuperseded) {
+			logErrorf("❌ Failed to set game polling rate: %v\n", err)
+		}
+		return
+	}
+	gw.appliedRate = rate
+	runSwitchHooks(gw.config, rate, game.Name)
+}
+
+// gamesChanged reports whether two detections found different sets of games
+func gamesChanged(previous, current []watchedGame) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	seen := make(map[string]bool, len(previous))
+	for _, game := range previous {
+		seen[game.Executable] = true
+	}
+	for _, game := range current {
+		if !seen[game.Executable] {
+			return true
+		}
+	}
+	return false
+}
+
+// gameRate returns the rate for a newly detected game. Refresh-rate tiers win
+// over resolution tiers; when neither can be measured game_polling_rate is used.
+func (gw *GameWatcher) gameRate(game watchedGame) int {
+	if rate, ok := gw.refreshTierRate(game); ok {
+		return rate
+	}
+	if rate, ok := gw.resolutionTierRate(game); ok {
+		return rate
+	}
+	if game.Rate != 0 {
+		return game.Rate
+	}
+	return gw.config.GamePollingRate
+}
+
+// refreshTierRate picks the game's rate from the refresh rate of its monitor
+func (gw *GameWatcher) refreshTierRate(game watchedGame) (int, bool) {
+	if len(game.RefreshRates) == 0 {
+		return 0, false
+	}
+
+	refresh, err := monitorRefreshRate(game.Executable)
+	if err != nil {
+		logDebugf("⚠️ Could not detect %s monitor refresh rate: %v\n", game.Name, err)
+		return 0, false
+	}
+
+	rate, ok := refreshRateRate(game.RefreshRates, refresh)
+	if ok && verbose {
+		logInfof("🖥️ %s is on a %dHz monitor, using %dHz\n", game.Name, refresh, rate)
+	}
+	return rate, ok
+}
+
+// resolutionTierRate picks the game's rate from its window height
+func (gw *GameWatcher) resolutionTierRate(game watchedGame) (int, bool) {
+	if len(game.ResolutionRates) == 0 {
+		return 0, false
+	}
+
+	width, height, err := windowSize(game.Executable)
+	if err != nil {
+		logDebugf("⚠️ Could not measure %s window: %v\n", game.Name, err)
+		return 0, false
+	}
+
+	rate, ok := resolutionRate(game.ResolutionRates, height)
+	if ok && verbose {
+		logInfof("🖥️ %s window is %dx%d, using %dHz\n", game.Name, width, height, rate)
+	}
+	return rate, ok
+}
+
+// updateModifier refreshes the active modifier rule and reports whether it changed
+func (gw *GameWatcher) updateModifier(processSet map[string]bool) bool {
+	modifier := activeModifier(gw.config, processSet)
+	if modifier == gw.modifier {
+		return false
+	}
+
+	if modifier != nil {
+		logInfof("📺 Modifier %q active: capping polling rate at %dHz\n", modifier.Name, modifier.MaxRate)
+	} else {
+		logInfof("📺 Modifier %q inactive: rate cap lifted\n", gw.modifier.Name)
+	}
+	gw.modifier = modifier
+	return true
+}
+
+// clampRate applies the active modifier's cap and max_polling_rate to the selected rate
+func (gw *GameWatcher) clampRate(rate int) int {
+	if gw.modifier != nil && rate > gw.modifier.MaxRate {
+		rate = gw.modifier.MaxRate
+	}
+	return capPollingRate(rate)
+}
+
+// applyModifiedRate re-applies the selected rate after the active modifier changed
+func (gw *GameWatcher) applyModifiedRate() {
+	rate := gw.clampRate(gw.targetRate)
+	if rate == gw.appliedRate {
+		return
+	}
+
+	logInfof("📺 Switching to %dHz\n", rate)
+	if err := gw.applyRate(rate); err != nil {
+		if !errors.Is(err, errSuperseded) {
+			logErrorf("❌ Failed to apply modified polling rate: %v\n", err)
+		}
+		return
+	}
+	gw.appliedRate = rate
+	runSwitchHooks(gw.config, rate, "")
+}
+
+// reassertGameRate re-applies the game rate every few checks while a game that
+// opted into reassert is running, countering games that reset the rate themselves
+func (gw *GameWatcher) reassertGameRate(running []watchedGame) {
+	var reassertGame *watchedGame
+	for i := range running {
+		if running[i].Reassert {
+			reassertGame = &running[i]
+			break
+		}
+	}
+	if reassertGame == nil {
+		gw.reassertCounter = 0
+		return
+	}
+
+	every := gw.config.ReassertTicks
+	if every <= 0 {
+		every = defaultReassertTicks
+	}
+
+	gw.reassertCounter++
+	if gw.reassertCounter < every {
+		return
+	}
+	gw.reassertCounter = 0
+
+	logDebugf("🔁 Re-applying %dHz for %s\n", gw.appliedRate, reassertGame.Name)
+	if err := gw.applyRate(gw.appliedRate); err != nil && !errors.Is(err, errSuperseded) {
+		logErrorf("❌ Failed to re-apply game polling rate: %v\n", err)
+	}
+}
+
+// applyScheduledRate switches the idle rate when the active schedule entry changes
+func (gw *GameWatcher) applyScheduledRate() {
+	rate := scheduledRate(gw.config, time.Now())
+	if rate == gw.baseRate {
+		return
+	}
+	gw.baseRate = rate
+	gw.targetRate = rate
+
+	rate = gw.clampRate(rate)
+	if rate == gw.appliedRate {
+		return
+	}
+
+	logInfof("🕐 Schedule changed. Switching to %dHz\n", rate)
+	if err := gw.applyRate(rate); err != nil {
+		if !errors.Is(err, errSuperseded) {
+			logErrorf("❌ Failed to set scheduled polling rate: %v\n", err)
+		}
+		return
+	}
+	gw.appliedRate = rate
+	runSwitchHooks(gw.config, rate, "")
+}
+
+// reconcileRate reads the device's actual rate and re-applies the expected one if
+// something else (official software, a game) changed it behind our back
+func (gw *GameWatcher) reconcileRate() {
+	if gw.paused.Load() || gw.suspendedBy != "" {
+		return
+	}
+
+	actual, err := gw.mouse.GetPollingRate()
+	if err != nil {
+		logDebugf("⚠️ Could not read polling rate for reconcile: %v\n", err)
+		return
+	}
+
+	expected := gw.appliedRate
+	if actual == expected {
+		return
+	}
+
+	logInfof("🔄 Polling rate changed externally to %dHz, re-applying %dHz\n", actual, expected)
+	gw.counters.add(&gw.counters.reconciles)
+	if err := gw.applyRate(expected); err != nil && !errors.Is(err, errSuperseded) {
+		logErrorf("❌ Failed to re-apply polling rate: %v\n", err)
+	}
+}
+
+// observeRate reads the device rate and reports when it differs from the last
+// reading, leaving control to whatever else changed it
+func (gw *GameWatcher) observeRate() {
+	gw.counters.add(&gw.counters.checks)
+
+	rate, err := gw.mouse.GetPollingRate()
+	if err != nil {
+		gw.counters.add(&gw.counters.errors)
+		logDebugf("⚠️ Could not read polling rate: %v\n", err)
+		return
+	}
+	if rate == gw.appliedRate {
+		return
+	}
+
+	logInfof("👀 Polling rate changed: %dHz -> %dHz\n", gw.appliedRate, rate)
+	gw.appliedRate = rate
+	gw.emit(WatchEvent{Type: EventRateObserved, Rate: rate})
+}
+
+// closeRate picks the rate to apply once the given games have all exited.
+// The highest per-game close rate wins; without one the scheduled default is used.
+func (gw *GameWatcher) closeRate(closed []watchedGame) int {
+	rate := 0
+	for _, game := range closed {
+		if game.CloseRate > rate {
+			rate = game.CloseRate
 		}
 	}
+
+	if rate == 0 {
+		gw.baseRate = scheduledRate(gw.config, time.Now())
+		return gw.baseRate
+	}
+
+	logDebugf("🔚 Using close rate override: %dHz\n", rate)
+	return rate
 }
 
 func (gw *GameWatcher) getRunningProcesses() ([]string, error) {
@@ -98,66 +984,377 @@ func (gw *GameWatcher) getRunningProcesses() ([]string, error) {
 		return nil, fmt.Errorf("failed to execute tasklist: %w", err)
 	}
 
-	// Parse CSV output
-	reader := csv.NewReader(strings.NewReader(string(output)))
+	includeSystem := allProcesses || gw.config.IncludeSystemProcesses
+
+	// Reuse existing slice to minimize allocations
+	names, err := parseTasklistCSV(output, includeSystem, gw.processCache[:0])
+	if err != nil {
+		return nil, err
+	}
+	gw.processCache = names
+	return names, nil
+}
+
+// parseTasklistCSV appends the normalized image names from `tasklist /fo csv /nh`
+// output to names, skipping system processes unless includeSystem is set
+func parseTasklistCSV(output []byte, includeSystem bool, names []string) ([]string, error) {
+	reader := csv.NewReader(bytes.NewReader(output))
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse tasklist output: %w", err)
 	}
 
-	// Reuse existing slice to minimize allocations
-	gw.processCache = gw.processCache[:0]
 	for _, record := range records {
 		if len(record) > 0 {
-			gw.processCache = append(gw.processCache, record[0])
+			name := normalizeProcessName(record[0])
+			if name == "" || (!includeSystem && isSystemProcess(name, record)) {
+				continue
+			}
+			names = append(names, name)
 		}
 	}
+	return names, nil
+}
 
-	return gw.processCache, nil
+// systemProcessNames are Windows processes that never belong to a game
+var systemProcessNames = map[string]bool{
+	"system":              true,
+	"system idle process": true,
+	"registry":            true,
+	"memory compression":  true,
+	"secure system":       true,
+	"smss.exe":            true,
+	"csrss.exe":           true,
+	"wininit.exe":         true,
+	"winlogon.exe":        true,
+	"services.exe":        true,
+	"lsass.exe":           true,
+	"lsaiso.exe":          true,
+	"svchost.exe":         true,
+	"fontdrvhost.exe":     true,
+	"dwm.exe":             true,
+	"spoolsv.exe":         true,
+	"wudfhost.exe":        true,
+	"dllhost.exe":         true,
+	"conhost.exe":         true,
+	"runtimebroker.exe":   true,
+	"sihost.exe":          true,
+	"taskhostw.exe":       true,
 }
 
-func (gw *GameWatcher) isAnyGameRunning(processes []string) bool {
-	processSet := make(map[string]bool)
-	for _, process := range processes {
-		processSet[strings.ToLower(process)] = true
+// isSystemProcess reports whether a tasklist row is a known system process or
+// runs in session 0, where services live and no game can show a window
+func isSystemProcess(name string, record []string) bool {
+	if systemProcessNames[strings.ToLower(name)] {
+		return true
 	}
+	// Columns: image name, PID, session name, session number, memory
+	return len(record) > 3 && strings.TrimSpace(record[3]) == "0"
+}
+
+// normalizeProcessName trims whitespace and drops the " *32" suffix Windows adds
+// to 32-bit processes on 64-bit systems, so "game.exe *32" matches "game.exe"
+func normalizeProcessName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimSpace(strings.TrimSuffix(name, "*32"))
+	return name
+}
+
+// watchedGames flattens the legacy, detected and custom game lists into one watch set
+func (gw *GameWatcher) watchedGames() []watchedGame {
+	return configuredGames(gw.config)
+}
+
+// configuredGames lists every game the config tells the watcher to look for
+func configuredGames(config *Config) []watchedGame {
+	return dedupeWatchedGames(config, listConfiguredGames(config), nil)
+}
+
+// gameDuplicate is a config entry folded into another entry for the same executable
+type gameDuplicate struct {
+	Duplicate watchedGame
+	Kept      watchedGame
+}
 
-	// Check legacy games list
-	for _, game := range gw.config.Games {
-		if processSet[strings.ToLower(game)] {
-			if verbose {
-				fmt.Printf("🎯 Detected game (legacy): %s\n", game)
+// duplicateGames lists the config entries the watcher folds into another one
+func duplicateGames(config *Config) []gameDuplicate {
+	var duplicates []gameDuplicate
+	dedupeWatchedGames(config, listConfiguredGames(config), func(duplicate, kept watchedGame) {
+		duplicates = append(duplicates, gameDuplicate{Duplicate: duplicate, Kept: kept})
+	})
+	return duplicates
+}
+
+// duplicateKeys indexes duplicateGames by duplicateKey, so listings can show
+// each game once
+func duplicateKeys(config *Config) map[string]bool {
+	keys := make(map[string]bool)
+	for _, d := range duplicateGames(config) {
+		keys[duplicateKey(d.Duplicate.Source, d.Duplicate.Name, d.Duplicate.Executable)] = true
+	}
+	return keys
+}
+
+// duplicateKey identifies a config entry by its section, name and executable
+func duplicateKey(source, name, executable string) string {
+	return source + "|" + strings.ToLower(name) + "|" + strings.ToLower(executable)
+}
+
+// logDuplicateGames reports, at debug level, the entries watched as one game
+func logDuplicateGames(config *Config) {
+	for _, d := range duplicateGames(config) {
+		logDebugf("🔁 %s (%s, %s) duplicates %s (%s), watching it once\n", d.Duplicate.Name, d.Duplicate.Source, d.Duplicate.Executable, d.Kept.Name, d.Kept.Source)
+	}
+}
+
+// listConfiguredGames flattens every game section of the config, duplicates included
+func listConfiguredGames(config *Config) []watchedGame {
+	games := make([]watchedGame, 0, config.gameCount())
+
+	for _, game := range config.Games {
+		games = append(games, watchedGame{Name: game, Executable: game, Source: sourceLegacy})
+	}
+
+	games = appendDetectedGames(games, config.DetectedGames, sourceSteam)
+	games = appendDetectedGames(games, config.DetectedEpicGames, sourceEpic)
+	games = appendDetectedGames(games, config.DetectedUbisoftGames, sourceUbisoft)
+
+	for _, game := range config.CustomGames {
+		games = append(games, watchedGame{
+			Disabled:        !gameEnabled(game.Enabled),
+			Name:            game.Name,
+			Executable:      game.Executable,
+			Source:          sourceCustom,
+			Rate:            game.PollingRate,
+			Path:            game.Path,
+			Parent:          game.ParentExecutable,
+			CloseRate:       game.CloseRate,
+			Reassert:        game.Reassert,
+			ResolutionRates: game.ResolutionRates,
+			RefreshRates:    game.RefreshRates,
+		})
+	}
+
+	for process, name := range config.ProcessAliases {
+		games = append(games, watchedGame{Name: name, Executable: process, Source: sourceAlias})
+	}
+
+	return games
+}
+
+// dedupeWatchedGames folds the detected and custom entries for the same
+// executable into one so it's watched once. The config keeps every entry; a
+// custom entry's settings win, the detected entries only fill in what it leaves
+// unset, and disabling any of them disables the game. report, if set, is
+// called for every entry folded into another.
+func dedupeWatchedGames(config *Config, games []watchedGame, report func(duplicate, kept watchedGame)) []watchedGame {
+	// owner[i] is the entry game i is merged into, itself if it's kept
+	owner := make([]int, len(games))
+	primary := make(map[string]int)
+	for i, game := range games {
+		owner[i] = i
+		if game.Executable == "" || !recordsSightings(game.Source) {
+			continue
+		}
+		key := matchKey(config, game.Executable)
+		j, seen := primary[key]
+		if !seen {
+			primary[key] = i
+			continue
+		}
+		if game.Source == sourceCustom && games[j].Source != sourceCustom {
+			// The custom entry takes over, along with everything merged so far
+			primary[key] = i
+			for k := range owner[:i] {
+				if owner[k] == j {
+					owner[k] = i
+				}
 			}
-			return true
+			continue
 		}
+		owner[i] = j
 	}
 
-	// Check detected Steam games
-	for _, game := range gw.config.DetectedGames {
-		if processSet[strings.ToLower(game.Executable)] {
-			if verbose {
-				fmt.Printf("🎯 Detected game: %s (%s)\n", game.Name, game.Executable)
+	for i, game := range games {
+		if owner[i] != i {
+			if report != nil {
+				report(game, games[owner[i]])
 			}
-			return true
+			games[owner[i]] = mergeWatchedGame(games[owner[i]], game)
 		}
 	}
 
-	// Check custom games
-	for _, game := range gw.config.CustomGames {
-		if processSet[strings.ToLower(game.Executable)] {
-			if verbose {
-				fmt.Printf("🎯 Detected custom game: %s (%s)\n", game.Name, game.Executable)
+	deduped := make([]watchedGame, 0, len(games))
+	for i, game := range games {
+		if owner[i] == i && !game.Disabled {
+			deduped = append(deduped, game)
+		}
+	}
+	return deduped
+}
+
+// mergeWatchedGame fills the settings the kept entry leaves unset from a
+// duplicate entry for the same executable
+func mergeWatchedGame(kept, duplicate watchedGame) watchedGame {
+	if kept.Rate == 0 {
+		kept.Rate = duplicate.Rate
+	}
+	if kept.CloseRate == 0 {
+		kept.CloseRate = duplicate.CloseRate
+	}
+	if kept.Path == "" {
+		kept.Path = duplicate.Path
+	}
+	if kept.Parent == "" {
+		kept.Parent = duplicate.Parent
+	}
+	if len(kept.ResolutionRates) == 0 {
+		kept.ResolutionRates = duplicate.ResolutionRates
+	}
+	if len(kept.RefreshRates) == 0 {
+		kept.RefreshRates = duplicate.RefreshRates
+	}
+	kept.Reassert = kept.Reassert || duplicate.Reassert
+	kept.Disabled = kept.Disabled || duplicate.Disabled
+	return kept
+}
+
+// appendDetectedGames adds the games of a scanned section; disabled ones are
+// dropped once duplicates are merged
+func appendDetectedGames(games []watchedGame, detected []Game, source string) []watchedGame {
+	for _, game := range detected {
+		games = append(games, watchedGame{
+			Disabled:        !gameEnabled(game.Enabled),
+			Name:            game.Name,
+			Executable:      game.Executable,
+			Source:          source,
+			Rate:            game.PollingRate,
+			Path:            game.InstallPath,
+			Parent:          game.ParentExecutable,
+			CloseRate:       game.CloseRate,
+			Reassert:        game.Reassert,
+			ResolutionRates: game.ResolutionRates,
+			RefreshRates:    game.RefreshRates,
+		})
+	}
+	return games
+}
+
+// buildProcessSet indexes process names by their match key
+func buildProcessSet(config *Config, processes []string) map[string]bool {
+	processSet := make(map[string]bool, len(processes))
+	for _, process := range processes {
+		processSet[matchKey(config, process)] = true
+	}
+	return processSet
+}
+
+// matchKey normalizes a process or configured executable name for comparison.
+// By default matching is case-insensitive on the full name; match_case_sensitive
+// and match_ignore_extension change that.
+func matchKey(config *Config, name string) string {
+	name = normalizeProcessName(name)
+	if config.MatchIgnoreExtension {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if !config.MatchCaseSensitive {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+// findRunningGames returns every watched game whose executable is in the process set
+func (gw *GameWatcher) findRunningGames(processSet map[string]bool) []watchedGame {
+	var running []watchedGame
+	var tree map[uint32]processEntry
+	for _, game := range gw.watchedGames() {
+		executable := matchKey(gw.config, game.Executable)
+		if executable == "" || !processSet[executable] {
+			continue
+		}
+
+		// Generic executables only count when launched by the expected parent;
+		// the snapshot is taken once per check and only when needed
+		if game.Parent != "" {
+			if tree == nil {
+				var err error
+				if tree, err = processTree(); err != nil {
+					logDebugf("⚠️ %v\n", err)
+					continue
+				}
 			}
-			return true
+			if !runsUnder(gw.config, tree, game.Executable, game.Parent) {
+				continue
+			}
+		}
+
+		if gw.config.IgnoreMinimized && gameMinimized(game.Executable) {
+			logDebugf("🔽 %s is minimized, not counting it as running\n", game.Name)
+			continue
+		}
+
+		switch game.Source {
+		case sourceLegacy:
+			logDebugf("🎯 Detected game (legacy): %s\n", game.Executable)
+		case sourceCustom:
+			logDebugf("🎯 Detected custom game: %s (%s)\n", game.Name, game.Executable)
+		case sourceAlias:
+			logDebugf("🎯 Detected aliased process: %s -> %s\n", game.Executable, game.Name)
+		default:
+			logDebugf("🎯 Detected game: %s (%s)\n", game.Name, game.Executable)
 		}
+		running = append(running, game)
 	}
 
-	return false
+	return running
 }
 
-func (gw *GameWatcher) GetStatus() (bool, int) {
-	if gw.isGameRunning {
-		return true, gw.config.GamePollingRate
+// applyRate sets the device rate and records the outcome in the watcher metrics
+// If the write fails the device is rediscovered and reopened once, since its
+// path may have changed after a replug.
+func (gw *GameWatcher) applyRate(rate int) error {
+	err := gw.mouse.SetPollingRate(rate)
+	if errors.Is(err, errSuperseded) {
+		logDebugf("⏭️ %dHz was superseded by a newer rate request, keeping %dHz as applied\n", rate, gw.appliedRate)
+		return err
+	}
+	if errors.Is(err, errUnsupportedRate) {
+		// The device is fine, reconnecting won't make it accept the rate
+		gw.counters.add(&gw.counters.errors)
+		return err
+	}
+	if err != nil {
+		if reopenErr := gw.mouse.Reopen(); reopenErr == nil {
+			gw.counters.add(&gw.counters.reconnects)
+			logInfof("🔌 Reconnected to mouse\n")
+			gw.emit(WatchEvent{Type: EventReconnected})
+			err = gw.mouse.SetPollingRate(rate)
+		} else {
+			logDebugf("⚠️ Reconnect failed: %v\n", reopenErr)
+		}
+	}
+
+	if err != nil {
+		gw.counters.add(&gw.counters.errors)
+		return err
+	}
+	gw.counters.add(&gw.counters.switches)
+	gw.emit(WatchEvent{Type: EventRateApplied, Rate: rate})
+	return nil
+}
+
+// TogglePause pauses or resumes switching and returns the new paused state.
+// While paused the current rate is left as-is.
+func (gw *GameWatcher) TogglePause() bool {
+	for {
+		paused := gw.paused.Load()
+		if gw.paused.CompareAndSwap(paused, !paused) {
+			return !paused
+		}
 	}
-	return false, gw.config.DefaultPollingRate
+}
+
+func (gw *GameWatcher) GetStatus() (bool, int) {
+	status := gw.currentStatus()
+	return status.running, status.rate
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestWatcher builds a watcher over a fake mouse that sees the given processes
+func newTestWatcher(t *testing.T, config *Config, mouse MouseControllerInterface, processes *[]string) *GameWatcher {
+	t.Helper()
+	watcher := NewGameWatcher(config, mouse)
+	watcher.recordSightings = false
+	watcher.processSource = func() ([]string, error) { return *processes, nil }
+	return watcher
+}
+
+// drainEvents returns the events queued so far
+func drainEvents(watcher *GameWatcher) []WatchEvent {
+	var events []WatchEvent
+	for {
+		select {
+		case event := <-watcher.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// TestSupersededRateIsNotRecorded checks that a write overtaken by a newer
+// request isn't treated as applied, nor as a device failure
+func TestSupersededRateIsNotRecorded(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomGames = []CustomGame{{Name: "Celeste", Executable: "Celeste.exe"}}
+
+	mouse := &fakeMouse{rate: config.DefaultPollingRate, setErr: errSuperseded}
+	processes := []string{"Celeste.exe"}
+	watcher := newTestWatcher(t, config, mouse, &processes)
+	before := watcher.appliedRate
+
+	watcher.checkProcesses()
+
+	if watcher.appliedRate != before {
+		t.Errorf("appliedRate = %d after a superseded write, want %d", watcher.appliedRate, before)
+	}
+	if mouse.reopened != 0 {
+		t.Errorf("superseded write reopened the device %d time(s)", mouse.reopened)
+	}
+	if metrics := watcher.Metrics(); metrics.Errors != 0 || metrics.Switches != 0 {
+		t.Errorf("metrics counted %d errors and %d switches, want none", metrics.Errors, metrics.Switches)
+	}
+	for _, event := range drainEvents(watcher) {
+		if event.Type == EventGameDetected || event.Type == EventError || event.Type == EventRateApplied {
+			t.Errorf("unexpected %v event for a superseded write", event.Type)
+		}
+	}
+}
+
+// tasklistOutput is raw `tasklist /fo csv /nh` output: CRLF line endings,
+// thousands separators in the memory column, and a 32-bit process annotated
+// with " *32"
+const tasklistOutput = "\"System Idle Process\",\"0\",\"Services\",\"0\",\"8 K\"\r\n" +
+	"\"svchost.exe\",\"1204\",\"Services\",\"0\",\"12,344 K\"\r\n" +
+	"\"explorer.exe\",\"6032\",\"Console\",\"1\",\"98,716 K\"\r\n" +
+	"\"LegacyGame.exe *32\",\"7440\",\"Console\",\"1\",\"1,204,552 K\"\r\n" +
+	"\"Celeste.exe \",\"8120\",\"Console\",\"1\",\"456,112 K\"\r\n"
+
+// TestParseTasklistCSV checks image names are normalized and session 0 and
+// system processes are skipped unless asked for
+func TestParseTasklistCSV(t *testing.T) {
+	names, err := parseTasklistCSV([]byte(tasklistOutput), false, nil)
+	if err != nil {
+		t.Fatalf("parseTasklistCSV: %v", err)
+	}
+	want := []string{"explorer.exe", "LegacyGame.exe", "Celeste.exe"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+
+	names, err = parseTasklistCSV([]byte(tasklistOutput), true, nil)
+	if err != nil {
+		t.Fatalf("parseTasklistCSV: %v", err)
+	}
+	if len(names) != 5 {
+		t.Errorf("with system processes got %q, want all 5 rows", names)
+	}
+}
+
+// TestTasklist32BitProcessMatches checks a 32-bit game listed as "name.exe *32"
+// still switches the rate
+func TestTasklist32BitProcessMatches(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomGames = []CustomGame{{Name: "Legacy Game", Executable: "legacygame.exe"}}
+
+	processes, err := parseTasklistCSV([]byte(tasklistOutput), false, nil)
+	if err != nil {
+		t.Fatalf("parseTasklistCSV: %v", err)
+	}
+	mouse := &fakeMouse{rate: config.DefaultPollingRate}
+	watcher := newTestWatcher(t, config, mouse, &processes)
+
+	watcher.checkProcesses()
+	if rate := mouse.currentRate(); rate != config.GamePollingRate {
+		t.Errorf("rate with the 32-bit game running = %d, want %d", rate, config.GamePollingRate)
+	}
+}
+
+// blockingMouse is a fake mouse whose writes hang until release is closed, like
+// a HID call that never returns
+type blockingMouse struct {
+	*fakeMouse
+	release chan struct{}
+}
+
+func (m *blockingMouse) SetPollingRate(rate int) error {
+	<-m.release
+	return m.fakeMouse.SetPollingRate(rate)
+}
+
+// TestBlockedDeviceCallDoesNotStallWatcher checks a write that never returns
+// times out instead of holding the watcher: ticks keep completing, status and
+// metrics answer, and Stop returns
+func TestBlockedDeviceCallDoesNotStallWatcher(t *testing.T) {
+	previousTimeout := deviceCallTimeout
+	deviceCallTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { deviceCallTimeout = previousTimeout })
+
+	config := DefaultConfig()
+	config.CheckInterval = 10 * time.Millisecond
+	config.CustomGames = []CustomGame{{Name: "Celeste", Executable: "Celeste.exe"}}
+
+	mouse := &blockingMouse{fakeMouse: &fakeMouse{rate: config.DefaultPollingRate}, release: make(chan struct{})}
+	defer close(mouse.release)
+	processes := []string{"Celeste.exe"}
+	watcher := newTestWatcher(t, config, mouse, &processes)
+
+	watcher.Start()
+	started := watcher.lastTick.Load()
+	checks := watcher.Metrics().Checks
+
+	deadline := time.Now().Add(2 * time.Second)
+	for watcher.lastTick.Load() == started || watcher.Metrics().Checks < checks+3 {
+		if time.Now().After(deadline) {
+			t.Fatal("no check completed while the device call was blocked")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if metrics := watcher.Metrics(); metrics.Errors == 0 {
+		t.Error("timed out writes weren't counted as errors")
+	}
+	if _, rate := watcher.GetStatus(); rate == config.GamePollingRate {
+		t.Errorf("GetStatus() reports the game rate %d, which was never written", rate)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		watcher.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop hung behind the blocked device call")
+	}
+}
+
+// TestObserveModeNeverWrites checks observe mode leaves the device alone even
+// with reconcile_interval set and a game running
+func TestObserveModeNeverWrites(t *testing.T) {
+	config := DefaultConfig()
+	config.CheckInterval = 10 * time.Millisecond
+	config.ReconcileInterval = 10 * time.Millisecond
+	config.CustomGames = []CustomGame{{Name: "Celeste", Executable: "Celeste.exe"}}
+
+	mouse := &fakeMouse{rate: 4000}
+	processes := []string{"Celeste.exe"}
+	watcher := newTestWatcher(t, config, mouse, &processes)
+	watcher.observeOnly = true
+	watcher.appliedRate = config.DefaultPollingRate
+
+	watcher.Start()
+	time.Sleep(100 * time.Millisecond)
+	watcher.Stop()
+
+	mouse.mu.Lock()
+	defer mouse.mu.Unlock()
+	if len(mouse.writes) != 0 {
+		t.Errorf("observe mode wrote %v to the device", mouse.writes)
+	}
+}
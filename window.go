@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// ResolutionRule selects a rate while the game's window is at most MaxHeight pixels tall
+type ResolutionRule struct {
+	MaxHeight int `yaml:"max_height"`
+	Rate      int `yaml:"rate"`
+}
+
+var (
+	user32                       = syscall.NewLazyDLL("user32.dll")
+	procEnumWindows              = user32.NewProc("EnumWindows")
+	procGetWindowThreadProcessId = user32.NewProc("GetWindowThreadProcessId")
+	procIsWindowVisible          = user32.NewProc("IsWindowVisible")
+	procGetWindowRect            = user32.NewProc("GetWindowRect")
+	procGetWindow                = user32.NewProc("GetWindow")
+	procIsIconic                 = user32.NewProc("IsIconic")
+)
+
+const gwOwner = 4
+
+type rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+// resolutionRate picks the rate tier matching the window height; ok is false
+// when no rule covers it
+func resolutionRate(rules []ResolutionRule, height int) (int, bool) {
+	sorted := append([]ResolutionRule(nil), rules...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MaxHeight < sorted[j].MaxHeight })
+
+	for _, rule := range sorted {
+		if height <= rule.MaxHeight {
+			return rule.Rate, true
+		}
+	}
+	return 0, false
+}
+
+// validateResolutionRules reports tiers with unusable heights or rates
+func validateResolutionRules(field string, rules []ResolutionRule) []error {
+	var problems []error
+	for i, rule := range rules {
+		if rule.MaxHeight <= 0 {
+			problems = append(problems, fmt.Errorf("%s.resolution_rates[%d]: max_height must be greater than zero", field, i))
+		}
+		if _, ok := pollingRateMap[rule.Rate]; !ok {
+			problems = append(problems, fmt.Errorf("%s.resolution_rates[%d]: unsupported rate %d", field, i, rule.Rate))
+		}
+	}
+	return problems
+}
+
+// windowSize measures the largest visible top-level window owned by the executable
+func windowSize(executable string) (width, height int, err error) {
+	_, r, err := gameWindow(executable)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(r.Right - r.Left), int(r.Bottom - r.Top), nil
+}
+
+// windowSearch is the state shared with the EnumWindows callback
+type windowSearch struct {
+	pids map[uint32]bool
+	hwnd uintptr
+	rect rect
+}
+
+var (
+	// EnumWindows callbacks can't be freed, so one is created and reused
+	windowSearchMu      sync.Mutex
+	currentWindowSearch *windowSearch
+	enumWindowsCallback = syscall.NewCallback(enumWindowsProc)
+)
+
+func enumWindowsProc(hwnd uintptr, _ uintptr) uintptr {
+	search := currentWindowSearch
+
+	var pid uint32
+	procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if !search.pids[pid] {
+		return 1
+	}
+
+	if visible, _, _ := procIsWindowVisible.Call(hwnd); visible == 0 {
+		return 1
+	}
+	if owner, _, _ := procGetWindow.Call(hwnd, gwOwner); owner != 0 {
+		return 1
+	}
+
+	var r rect
+	if ret, _, _ := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&r))); ret == 0 {
+		return 1
+	}
+
+	area := int64(r.Right-r.Left) * int64(r.Bottom-r.Top)
+	best := int64(search.rect.Right-search.rect.Left) * int64(search.rect.Bottom-search.rect.Top)
+	if area > best {
+		search.hwnd = hwnd
+		search.rect = r
+	}
+	return 1
+}
+
+// gameWindow finds the largest visible top-level window owned by the executable
+func gameWindow(executable string) (uintptr, rect, error) {
+	pids, err := processIDs(executable)
+	if err != nil {
+		return 0, rect{}, err
+	}
+	if len(pids) == 0 {
+		return 0, rect{}, fmt.Errorf("no process found for %s", executable)
+	}
+
+	windowSearchMu.Lock()
+	defer windowSearchMu.Unlock()
+
+	search := &windowSearch{pids: pids}
+	currentWindowSearch = search
+	procEnumWindows.Call(enumWindowsCallback, 0)
+	currentWindowSearch = nil
+
+	if search.hwnd == 0 || search.rect.Right == search.rect.Left || search.rect.Bottom == search.rect.Top {
+		return 0, rect{}, fmt.Errorf("no visible window for %s", executable)
+	}
+	return search.hwnd, search.rect, nil
+}
+
+// gameMinimized reports whether the executable's main window is minimized. A
+// game without a visible window (e.g. still loading) is not considered minimized.
+func gameMinimized(executable string) bool {
+	hwnd, _, err := gameWindow(executable)
+	if err != nil {
+		return false
+	}
+	iconic, _, _ := procIsIconic.Call(hwnd)
+	return iconic != 0
+}
+
+// defaultForegroundIgnore lists shell, overlay and notification hosts that take
+// focus briefly without the user leaving the game
+var defaultForegroundIgnore = []string{
+	"explorer.exe",
+	"ShellExperienceHost.exe",
+	"StartMenuExperienceHost.exe",
+	"SearchHost.exe",
+	"ApplicationFrameHost.exe",
+	"gameoverlayui.exe", // Steam overlay
+	"GameBar.exe",       // Xbox Game Bar
+	"NVIDIA Share.exe",  // GeForce overlay
+	"Discord.exe",       // Discord overlay
+	"LockApp.exe",
+}
+
+// foregroundIgnored reports whether a foreground executable should be treated as
+// if focus never moved: the automator itself, the built-in list and foreground_ignore
+func foregroundIgnored(config *Config, executable string) bool {
+	key := matchKey(config, executable)
+
+	if self, err := os.Executable(); err == nil && matchKey(config, filepath.Base(self)) == key {
+		return true
+	}
+	for _, name := range defaultForegroundIgnore {
+		if matchKey(config, name) == key {
+			return true
+		}
+	}
+	for _, name := range config.ForegroundIgnore {
+		if matchKey(config, name) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// foregroundApp returns the full executable path of the foreground window's
+// process and whether that window covers its whole monitor
+func foregroundApp() (path string, fullscreen bool, err error) {
+	hwnd := windows.GetForegroundWindow()
+	if hwnd == 0 {
+		return "", false, fmt.Errorf("no foreground window")
+	}
+
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(hwnd, &pid); err != nil {
+		return "", false, fmt.Errorf("failed to get foreground process: %w", err)
+	}
+
+	path, err = imagePathForPID(pid)
+	if err != nil {
+		return "", false, err
+	}
+
+	var r rect
+	if ret, _, _ := procGetWindowRect.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&r))); ret == 0 {
+		return path, false, nil
+	}
+
+	monitor, _, _ := procMonitorFromWindow.Call(uintptr(hwnd), monitorDefaultToNearest)
+	info := monitorInfoEx{CbSize: uint32(unsafe.Sizeof(monitorInfoEx{}))}
+	if ret, _, _ := procGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&info))); ret == 0 {
+		return path, false, nil
+	}
+
+	fullscreen = r.Left <= info.Monitor.Left && r.Top <= info.Monitor.Top &&
+		r.Right >= info.Monitor.Right && r.Bottom >= info.Monitor.Bottom
+	return path, fullscreen, nil
+}